@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apolloLongPollTimeout 通知接口的挂起时间（服务端固定为60秒）
+const apolloLongPollTimeout = 60 * time.Second
+
+// ApolloSourceConfig Apollo配置源连接配置
+type ApolloSourceConfig struct {
+	ConfigServer string `yaml:"config_server"` // Config Service地址，如 http://127.0.0.1:8080
+	AppID        string `yaml:"app_id"`
+	Cluster      string `yaml:"cluster"`   // 集群，默认 default
+	Namespace    string `yaml:"namespace"` // YAML格式的命名空间，默认 domain-exporter.yaml
+	Secret       string `yaml:"secret"`    // 访问密钥（可选）
+}
+
+// ApolloConfigSource 基于Apollo HTTP接口（/configs、/notifications/v2）的配置源
+type ApolloConfigSource struct {
+	configHolder
+	settings       ApolloSourceConfig
+	httpClient     *http.Client
+	notifyClient   *http.Client // 长轮询专用客户端，超时时间需大于挂起时间
+	releaseKey     string       // 当前配置的发布版本
+	notificationID int64        // 最近一次收到的通知ID
+	ctx            context.Context
+	cancel         context.CancelFunc
+}
+
+// NewApolloConfigSource 创建Apollo配置源
+func NewApolloConfigSource(localConfig *Config) (*ApolloConfigSource, error) {
+	settings := localConfig.Apollo
+	if settings.ConfigServer == "" || settings.AppID == "" {
+		return nil, fmt.Errorf("未配置Apollo地址或AppID（apollo.config_server、apollo.app_id）")
+	}
+	settings.ConfigServer = strings.TrimRight(settings.ConfigServer, "/")
+
+	slog.Info("创建Apollo配置源",
+		"config_server", settings.ConfigServer,
+		"app_id", settings.AppID,
+		"cluster", settings.Cluster,
+		"namespace", settings.Namespace)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	httpClient := newSourceHTTPClient(15*time.Second, localConfig.SkipSSLVerify)
+	source := &ApolloConfigSource{
+		configHolder:   newConfigHolder(localConfig),
+		settings:       settings,
+		httpClient:     httpClient,
+		notifyClient:   &http.Client{Timeout: apolloLongPollTimeout + 30*time.Second, Transport: httpClient.Transport},
+		notificationID: -1,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+
+	// 初始加载配置
+	if err := source.load(); err != nil {
+		slog.Warn("初始配置加载失败，将使用本地配置", "source", "apollo", "error", err)
+	}
+
+	go source.watch()
+
+	return source, nil
+}
+
+// Name 配置源名称
+func (s *ApolloConfigSource) Name() string {
+	return "apollo"
+}
+
+// watch 通过通知接口长轮询监听配置发布，失败时回退到定期拉取
+func (s *ApolloConfigSource) watch() {
+	slog.Info("启动Apollo配置监听", "long_poll_timeout", apolloLongPollTimeout, "fallback_interval", configSourcePollInterval)
+
+	for {
+		changed, err := s.poll()
+		if s.ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			slog.Debug("Apollo长轮询失败，回退到定期拉取", "error", err)
+			if err := s.load(); err != nil {
+				slog.Debug("Apollo配置拉取失败", "error", err)
+			}
+			if !sleepContext(s.ctx, configSourcePollInterval) {
+				break
+			}
+			continue
+		}
+
+		if changed {
+			slog.Debug("Apollo通知配置已发布，重新加载")
+			if err := s.load(); err != nil {
+				slog.Debug("Apollo配置拉取失败", "error", err)
+			}
+		}
+	}
+
+	slog.Info("停止Apollo配置监听")
+}
+
+// poll 发起一次通知长轮询，返回配置是否发生变化
+func (s *ApolloConfigSource) poll() (bool, error) {
+	notifications, err := json.Marshal([]map[string]interface{}{
+		{"namespaceName": s.settings.Namespace, "notificationId": s.notificationID},
+	})
+	if err != nil {
+		return false, fmt.Errorf("序列化通知请求失败: %w", err)
+	}
+
+	query := url.Values{
+		"appId":         {s.settings.AppID},
+		"cluster":       {s.settings.Cluster},
+		"notifications": {string(notifications)},
+	}
+	resp, err := s.get(s.notifyClient, "/notifications/v2?"+query.Encode())
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	// 挂起超时且没有变化时返回304
+	if resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("读取通知响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("通知请求失败: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var results []struct {
+		NamespaceName  string `json:"namespaceName"`
+		NotificationID int64  `json:"notificationId"`
+	}
+	if err := json.Unmarshal(body, &results); err != nil {
+		return false, fmt.Errorf("解析通知响应失败: %w", err)
+	}
+
+	changed := false
+	for _, result := range results {
+		if result.NamespaceName == s.settings.Namespace && result.NotificationID != s.notificationID {
+			s.notificationID = result.NotificationID
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// load 拉取命名空间配置，发布版本未变化时服务端返回304
+func (s *ApolloConfigSource) load() error {
+	path := fmt.Sprintf("/configs/%s/%s/%s", url.PathEscape(s.settings.AppID), url.PathEscape(s.settings.Cluster), url.PathEscape(s.settings.Namespace))
+	if s.releaseKey != "" {
+		path += "?" + url.Values{"releaseKey": {s.releaseKey}}.Encode()
+	}
+
+	resp, err := s.get(s.httpClient, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取配置响应失败: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("配置不存在: %s", s.settings.Namespace)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("获取配置失败: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var configResp struct {
+		Configurations map[string]string `json:"configurations"`
+		ReleaseKey     string            `json:"releaseKey"`
+	}
+	if err := json.Unmarshal(body, &configResp); err != nil {
+		return fmt.Errorf("解析配置响应失败: %w", err)
+	}
+
+	// YAML格式的命名空间内容保存在content键中
+	content, ok := configResp.Configurations["content"]
+	if !ok || content == "" {
+		return fmt.Errorf("命名空间中没有content，请使用YAML格式的命名空间: %s", s.settings.Namespace)
+	}
+
+	if err := s.applyContent("apollo", content); err != nil {
+		return err
+	}
+	s.releaseKey = configResp.ReleaseKey
+	return nil
+}
+
+// get 发送GET请求，配置了访问密钥时附加签名
+func (s *ApolloConfigSource) get(client *http.Client, pathWithQuery string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, s.settings.ConfigServer+pathWithQuery, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	if s.settings.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha1.New, []byte(s.settings.Secret))
+		mac.Write([]byte(timestamp + "\n" + pathWithQuery))
+		signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		req.Header.Set("Authorization", fmt.Sprintf("Apollo %s:%s", s.settings.AppID, signature))
+		req.Header.Set("Timestamp", timestamp)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	return resp, nil
+}
+
+// Close 停止监听
+func (s *ApolloConfigSource) Close() {
+	s.cancel()
+	slog.Info("Apollo配置源已关闭")
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+
+	whoisparser "github.com/likexian/whois-parser"
+)
+
+// 域名查询的错误分类，查询函数通过%w包装，调用方可用errors.Is区分失败原因
+var (
+	ErrLookupTimeout   = errors.New("查询超时")
+	ErrLookupNetwork   = errors.New("网络错误")
+	ErrRateLimited     = errors.New("查询被限速")
+	ErrDomainNotFound  = errors.New("域名未注册")
+	ErrUnparseableDate = errors.New("无法解析日期")
+	ErrMissingExpiry   = errors.New("缺少过期时间字段")
+	ErrInvalidResponse = errors.New("响应格式无效")
+)
+
+// isPermanentLookupError 判断是否为重试也无法成功的错误（域名未注册、响应无法解析等）
+func isPermanentLookupError(err error) bool {
+	return errors.Is(err, ErrDomainNotFound) ||
+		errors.Is(err, ErrUnparseableDate) ||
+		errors.Is(err, ErrMissingExpiry) ||
+		errors.Is(err, ErrInvalidResponse)
+}
+
+// wrapNetworkError 为连接或请求错误附加分类：超时归为ErrLookupTimeout，其他归为ErrLookupNetwork，取消时原样返回
+func wrapNetworkError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %w", ErrLookupTimeout, err)
+	}
+	return fmt.Errorf("%w: %w", ErrLookupNetwork, err)
+}
+
+// wrapWhoisParserError 将whois-parser的错误映射到查询错误分类
+func wrapWhoisParserError(err error) error {
+	switch {
+	case errors.Is(err, whoisparser.ErrNotFoundDomain),
+		errors.Is(err, whoisparser.ErrPremiumDomain),
+		errors.Is(err, whoisparser.ErrReservedDomain),
+		errors.Is(err, whoisparser.ErrBlockedDomain):
+		return fmt.Errorf("%w: %w", ErrDomainNotFound, err)
+	case errors.Is(err, whoisparser.ErrDomainLimitExceed):
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+}
+
+// 检查失败原因，作为domain_check_errors_total的reason标签
+const (
+	checkErrorTimeout        = "timeout"
+	checkErrorConnectRefused = "connect_refused"
+	checkErrorNetwork        = "network"
+	checkErrorRateLimited    = "rate_limited"
+	checkErrorParseFailed    = "parse_failed"
+	checkErrorNotFound       = "not_found"
+	checkErrorOther          = "other"
+)
+
+// classifyCheckError 归类检查失败原因，自定义提供者返回未分类的错误时根据错误信息判断
+func classifyCheckError(err error) string {
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return checkErrorRateLimited
+	case errors.Is(err, ErrLookupTimeout):
+		return checkErrorTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return checkErrorConnectRefused
+	case errors.Is(err, ErrLookupNetwork):
+		return checkErrorNetwork
+	case errors.Is(err, ErrDomainNotFound):
+		return checkErrorNotFound
+	case errors.Is(err, ErrUnparseableDate), errors.Is(err, ErrMissingExpiry), errors.Is(err, ErrInvalidResponse):
+		return checkErrorParseFailed
+	}
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case containsAny(message, "http 429", "rate limit", "too many requests"):
+		return checkErrorRateLimited
+	case errors.Is(err, context.DeadlineExceeded) || containsAny(message, "timeout", "超时"):
+		return checkErrorTimeout
+	case containsAny(message, "connection refused"):
+		return checkErrorConnectRefused
+	case containsAny(message, "not found", "no match"):
+		return checkErrorNotFound
+	default:
+		return checkErrorOther
+	}
+}
+
+// containsAny 判断s是否包含任一子串
+func containsAny(s string, substrs ...string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
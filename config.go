@@ -1,21 +1,46 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
-
-	"gopkg.in/yaml.v2"
 )
 
 // Config 配置结构
 type Config struct {
 	// 业务配置（从Nacos获取）
-	Domains       []string `yaml:"domains"`
-	CheckInterval int      `yaml:"check_interval"`
-	Port          int      `yaml:"port"`
-	LogLevel      string   `yaml:"log_level"`
-	Timeout       int      `yaml:"timeout"`
+	Domains       []DomainEntry `yaml:"domains"` // 域名列表，支持纯字符串或带标签/覆盖项的对象
+	CheckInterval int           `yaml:"check_interval"`
+	Port          int           `yaml:"port"`
+	LogLevel      string        `yaml:"log_level"`
+	Timeout       int           `yaml:"timeout"`
+	Concurrency   int           `yaml:"concurrency"` // 并发检查的worker数量
+
+	// 允许配置更新清空域名列表（默认拒绝域名列表为空的配置更新，避免误推送删除所有指标）
+	AllowEmptyDomains bool `yaml:"allow_empty_domains"`
+
+	// 兼容旧版本：检查失败时写入 domain_expiry_days=-999、domain_expiry_timestamp=0，而不是保留上次成功获取的值
+	LegacyFailureSentinel bool `yaml:"legacy_failure_sentinel"`
+
+	// 其他域名来源（文件、HTTP、Nacos dataId等），与domains合并去重
+	DomainSources []DomainSourceConfig `yaml:"domain_sources"`
+
+	// 域名信息提供者链（如 rdap, whois, manual），可按TLD覆盖
+	Providers    ProviderConfig    `yaml:"providers"`
+	ManualExpiry map[string]string `yaml:"manual_expiry"` // 手动维护的过期时间（域名 -> 日期），供manual提供者使用
+
+	// 按WHOIS/RDAP服务器限速
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+
+	// 按剩余天数自适应的检查间隔
+	Schedule ScheduleConfig `yaml:"schedule"`
+
+	// TLS证书过期检查
+	TLSCheck TLSCheckConfig `yaml:"tls_check"`
+
+	// 配置源类型: nacos, consul, etcd, apollo, file，为空时根据nacos_url和-config参数自动选择
+	ConfigSource string `yaml:"config_source"`
 
 	// Nacos连接配置（从本地配置文件获取）
 	NacosUrl      string `yaml:"nacos_url"`
@@ -25,30 +50,59 @@ type Config struct {
 	DataId        string `yaml:"data_id"`
 	Group         string `yaml:"group"`
 	SkipSSLVerify bool   `yaml:"skip_ssl_verify"` // 跳过SSL证书验证
+
+	NacosAPIVersion string `yaml:"nacos_api_version"` // 配置API版本: auto（默认，按服务端版本检测）、v1、v2
+
+	// 其他配置中心连接配置（从本地配置文件获取）
+	Consul ConsulSourceConfig `yaml:"consul"`
+	Etcd   EtcdSourceConfig   `yaml:"etcd"`
+	Apollo ApolloSourceConfig `yaml:"apollo"`
+
+	// 状态文件路径（从本地配置文件获取），为空时不持久化检查结果
+	StateFile string `yaml:"state_file"`
+
+	// 本地配置文件路径（来自命令行参数），未启用Nacos时监听其变化
+	ConfigFile string `yaml:"-"`
 }
 
 // LoadConfig 加载配置（优先使用环境变量，然后是配置文件）
 func LoadConfig(filename string) (*Config, error) {
+	var fileConfig *Config
+
+	// 指定了配置文件时严格解析并校验，再与环境变量合并（环境变量优先）
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		parsed, err := ParseConfig(data)
+		if err != nil {
+			return nil, fmt.Errorf("配置文件 %s 无效: %w", filename, err)
+		}
+		fileConfig = parsed
+	}
+
+	config := buildConfig(fileConfig)
+	config.ConfigFile = filename
+	return config, nil
+}
+
+// buildConfig 以环境变量为准合并配置文件内容（可为nil）并应用默认值
+func buildConfig(fileConfig *Config) *Config {
 	var config Config
 
-	// 首先尝试从环境变量加载
+	// 首先从环境变量加载
 	loadFromEnv(&config)
 
-	// 如果配置文件存在，则加载并合并（环境变量优先）
-	if filename != "" {
-		if data, err := os.ReadFile(filename); err == nil {
-			var fileConfig Config
-			if err := yaml.Unmarshal(data, &fileConfig); err == nil {
-				// 合并配置，环境变量优先
-				mergeConfig(&config, &fileConfig)
-			}
-		}
+	// 合并配置，环境变量优先
+	if fileConfig != nil {
+		mergeConfig(&config, fileConfig)
 	}
 
 	// 应用默认值
 	applyDefaults(&config)
 
-	return &config, nil
+	return &config
 }
 
 // IsNacosEnabled 检查是否启用了Nacos
@@ -80,13 +134,69 @@ func loadFromEnv(config *Config) {
 	if val := os.Getenv("NACOS_SKIP_SSL_VERIFY"); val != "" {
 		config.SkipSSLVerify = val == "true" || val == "1"
 	}
+	if val := os.Getenv("NACOS_API_VERSION"); val != "" {
+		config.NacosAPIVersion = val
+	}
+	if val := os.Getenv("STATE_FILE"); val != "" {
+		config.StateFile = val
+	}
+
+	// 其他配置中心
+	if val := os.Getenv("CONFIG_SOURCE"); val != "" {
+		config.ConfigSource = val
+	}
+	if val := os.Getenv("CONSUL_HTTP_ADDR"); val != "" {
+		config.Consul.Address = val
+	}
+	if val := os.Getenv("CONSUL_HTTP_TOKEN"); val != "" {
+		config.Consul.Token = val
+	}
+	if val := os.Getenv("CONSUL_KEY"); val != "" {
+		config.Consul.Key = val
+	}
+	if val := os.Getenv("CONSUL_DATACENTER"); val != "" {
+		config.Consul.Datacenter = val
+	}
+	if val := os.Getenv("ETCD_ENDPOINTS"); val != "" {
+		for _, endpoint := range strings.Split(val, ",") {
+			if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+				config.Etcd.Endpoints = append(config.Etcd.Endpoints, endpoint)
+			}
+		}
+	}
+	if val := os.Getenv("ETCD_USERNAME"); val != "" {
+		config.Etcd.Username = val
+	}
+	if val := os.Getenv("ETCD_PASSWORD"); val != "" {
+		config.Etcd.Password = val
+	}
+	if val := os.Getenv("ETCD_KEY"); val != "" {
+		config.Etcd.Key = val
+	}
+	if val := os.Getenv("APOLLO_CONFIG_SERVER"); val != "" {
+		config.Apollo.ConfigServer = val
+	}
+	if val := os.Getenv("APOLLO_APP_ID"); val != "" {
+		config.Apollo.AppID = val
+	}
+	if val := os.Getenv("APOLLO_CLUSTER"); val != "" {
+		config.Apollo.Cluster = val
+	}
+	if val := os.Getenv("APOLLO_NAMESPACE"); val != "" {
+		config.Apollo.Namespace = val
+	}
+	if val := os.Getenv("APOLLO_SECRET"); val != "" {
+		config.Apollo.Secret = val
+	}
 
 	// 业务配置
 	if val := os.Getenv("DOMAINS"); val != "" {
-		config.Domains = strings.Split(val, ",")
-		// 清理空白字符
-		for i, domain := range config.Domains {
-			config.Domains[i] = strings.TrimSpace(domain)
+		config.Domains = nil
+		for _, domain := range strings.Split(val, ",") {
+			// 清理空白字符
+			if domain = strings.TrimSpace(domain); domain != "" {
+				config.Domains = append(config.Domains, DomainEntry{Name: domain})
+			}
 		}
 	}
 	if val := os.Getenv("CHECK_INTERVAL"); val != "" {
@@ -108,6 +218,14 @@ func loadFromEnv(config *Config) {
 			config.Timeout = timeout
 		}
 	}
+	if val := os.Getenv("CONCURRENCY"); val != "" {
+		if concurrency, err := strconv.Atoi(val); err == nil {
+			config.Concurrency = concurrency
+		}
+	}
+	if val := os.Getenv("LEGACY_FAILURE_SENTINEL"); val != "" {
+		config.LegacyFailureSentinel = val == "true" || val == "1"
+	}
 
 }
 
@@ -132,6 +250,59 @@ func mergeConfig(envConfig, fileConfig *Config) {
 	if envConfig.Group == "" {
 		envConfig.Group = fileConfig.Group
 	}
+	if envConfig.NacosAPIVersion == "" {
+		envConfig.NacosAPIVersion = fileConfig.NacosAPIVersion
+	}
+	if !envConfig.SkipSSLVerify {
+		envConfig.SkipSSLVerify = fileConfig.SkipSSLVerify
+	}
+	if envConfig.StateFile == "" {
+		envConfig.StateFile = fileConfig.StateFile
+	}
+
+	// 其他配置中心
+	if envConfig.ConfigSource == "" {
+		envConfig.ConfigSource = fileConfig.ConfigSource
+	}
+	if envConfig.Consul.Address == "" {
+		envConfig.Consul.Address = fileConfig.Consul.Address
+	}
+	if envConfig.Consul.Token == "" {
+		envConfig.Consul.Token = fileConfig.Consul.Token
+	}
+	if envConfig.Consul.Key == "" {
+		envConfig.Consul.Key = fileConfig.Consul.Key
+	}
+	if envConfig.Consul.Datacenter == "" {
+		envConfig.Consul.Datacenter = fileConfig.Consul.Datacenter
+	}
+	if len(envConfig.Etcd.Endpoints) == 0 {
+		envConfig.Etcd.Endpoints = fileConfig.Etcd.Endpoints
+	}
+	if envConfig.Etcd.Username == "" {
+		envConfig.Etcd.Username = fileConfig.Etcd.Username
+	}
+	if envConfig.Etcd.Password == "" {
+		envConfig.Etcd.Password = fileConfig.Etcd.Password
+	}
+	if envConfig.Etcd.Key == "" {
+		envConfig.Etcd.Key = fileConfig.Etcd.Key
+	}
+	if envConfig.Apollo.ConfigServer == "" {
+		envConfig.Apollo.ConfigServer = fileConfig.Apollo.ConfigServer
+	}
+	if envConfig.Apollo.AppID == "" {
+		envConfig.Apollo.AppID = fileConfig.Apollo.AppID
+	}
+	if envConfig.Apollo.Cluster == "" {
+		envConfig.Apollo.Cluster = fileConfig.Apollo.Cluster
+	}
+	if envConfig.Apollo.Namespace == "" {
+		envConfig.Apollo.Namespace = fileConfig.Apollo.Namespace
+	}
+	if envConfig.Apollo.Secret == "" {
+		envConfig.Apollo.Secret = fileConfig.Apollo.Secret
+	}
 
 	// 业务配置
 	if len(envConfig.Domains) == 0 {
@@ -150,6 +321,19 @@ func mergeConfig(envConfig, fileConfig *Config) {
 	if envConfig.Timeout == 0 {
 		envConfig.Timeout = fileConfig.Timeout
 	}
+	if envConfig.Concurrency == 0 {
+		envConfig.Concurrency = fileConfig.Concurrency
+	}
+	envConfig.Providers = fileConfig.Providers
+	envConfig.ManualExpiry = fileConfig.ManualExpiry
+	envConfig.RateLimit = fileConfig.RateLimit
+	envConfig.Schedule = fileConfig.Schedule
+	envConfig.TLSCheck = fileConfig.TLSCheck
+	envConfig.DomainSources = fileConfig.DomainSources
+	envConfig.AllowEmptyDomains = fileConfig.AllowEmptyDomains
+	if !envConfig.LegacyFailureSentinel {
+		envConfig.LegacyFailureSentinel = fileConfig.LegacyFailureSentinel
+	}
 
 }
 
@@ -169,6 +353,21 @@ func applyDefaults(config *Config) {
 	if config.Timeout == 0 {
 		config.Timeout = 30 // 默认超时30秒
 	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 5 // 默认5个并发worker
+	}
+	if config.RateLimit.QueriesPerMinute <= 0 {
+		config.RateLimit.QueriesPerMinute = 30 // 默认每个服务器每分钟30次
+	}
+	if config.RateLimit.Burst <= 0 {
+		config.RateLimit.Burst = 3
+	}
+	if config.Schedule.ExpiredInterval <= 0 {
+		config.Schedule.ExpiredInterval = 900 // 已过期域名默认每15分钟检查一次
+	}
+	if config.TLSCheck.Port <= 0 {
+		config.TLSCheck.Port = 443
+	}
 
 	// Nacos连接配置默认值
 	if config.DataId == "" {
@@ -180,4 +379,21 @@ func applyDefaults(config *Config) {
 	if config.NamespaceId == "" {
 		config.NamespaceId = "public"
 	}
+	if config.NacosAPIVersion == "" {
+		config.NacosAPIVersion = "auto"
+	}
+
+	// 其他配置中心连接配置默认值
+	if config.Consul.Key == "" {
+		config.Consul.Key = "domain-exporter"
+	}
+	if config.Etcd.Key == "" {
+		config.Etcd.Key = "/domain-exporter"
+	}
+	if config.Apollo.Cluster == "" {
+		config.Apollo.Cluster = "default"
+	}
+	if config.Apollo.Namespace == "" {
+		config.Apollo.Namespace = "domain-exporter.yaml"
+	}
 }
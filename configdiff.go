@@ -0,0 +1,165 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ConfigUpdate 配置更新通知，携带新配置和相对上一次配置的变更集
+type ConfigUpdate struct {
+	Previous *Config // 变更前的配置
+	Config   *Config
+	Change   ConfigChange
+}
+
+// NewConfigUpdate 比较新旧配置生成配置更新通知
+func NewConfigUpdate(previous, config *Config) *ConfigUpdate {
+	return &ConfigUpdate{Previous: previous, Config: config, Change: DiffConfig(previous, config)}
+}
+
+// ConfigChange 配置变更集
+type ConfigChange struct {
+	AddedDomains   []string               // 新增的域名
+	RemovedDomains []string               // 删除的域名
+	ChangedDomains []string               // 标签或覆盖项发生变化的域名
+	LookupChanged  []string               // 影响查询结果的覆盖项（timeout、providers、tls）发生变化的域名，是ChangedDomains的子集
+	Fields         map[string]FieldChange // 发生变化的业务字段（键为yaml字段名）
+}
+
+// FieldChange 单个字段的新旧值
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// connectionFields 连接配置字段，来自本地配置，不参与业务配置比较
+var connectionFields = map[string]struct{}{
+	"nacos_url":         {},
+	"username":          {},
+	"password":          {},
+	"namespace_id":      {},
+	"data_id":           {},
+	"group":             {},
+	"skip_ssl_verify":   {},
+	"nacos_api_version": {},
+	"state_file":        {},
+	"config_source":     {},
+	"consul":            {},
+	"etcd":              {},
+	"apollo":            {},
+}
+
+// copyConnectionFields 将本地连接配置字段复制到从配置中心获取的配置中
+func copyConnectionFields(dst, src *Config) {
+	dstValue := reflect.ValueOf(dst).Elem()
+	srcValue := reflect.ValueOf(src).Elem()
+	configType := dstValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		name := strings.Split(configType.Field(i).Tag.Get("yaml"), ",")[0]
+		if _, ok := connectionFields[name]; ok || name == "-" {
+			dstValue.Field(i).Set(srcValue.Field(i))
+		}
+	}
+}
+
+// lookupFields 影响查询结果的字段，变化后需要重新检查所有域名
+var lookupFields = []string{"providers", "manual_expiry", "tls_check"}
+
+// DiffConfig 比较两份配置，返回完整的结构化变更集
+func DiffConfig(oldConfig, newConfig *Config) ConfigChange {
+	change := ConfigChange{Fields: make(map[string]FieldChange)}
+	if oldConfig == nil {
+		oldConfig = &Config{}
+	}
+
+	// 比较域名集合及每个域名的配置
+	oldDomains := make(map[string]DomainEntry, len(oldConfig.Domains))
+	for _, entry := range oldConfig.Domains {
+		oldDomains[entry.Name] = entry
+	}
+	newDomains := make(map[string]DomainEntry, len(newConfig.Domains))
+	for _, entry := range newConfig.Domains {
+		newDomains[entry.Name] = entry
+	}
+
+	for name, entry := range newDomains {
+		oldEntry, ok := oldDomains[name]
+		if !ok {
+			change.AddedDomains = append(change.AddedDomains, name)
+		} else if !reflect.DeepEqual(oldEntry, entry) {
+			change.ChangedDomains = append(change.ChangedDomains, name)
+			if lookupEntryChanged(oldEntry, entry) {
+				change.LookupChanged = append(change.LookupChanged, name)
+			}
+		}
+	}
+	for name := range oldDomains {
+		if _, ok := newDomains[name]; !ok {
+			change.RemovedDomains = append(change.RemovedDomains, name)
+		}
+	}
+	sort.Strings(change.AddedDomains)
+	sort.Strings(change.RemovedDomains)
+	sort.Strings(change.ChangedDomains)
+	sort.Strings(change.LookupChanged)
+
+	// 逐个比较业务字段
+	oldValue := reflect.ValueOf(*oldConfig)
+	newValue := reflect.ValueOf(*newConfig)
+	configType := oldValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		name := strings.Split(configType.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" || name == "domains" {
+			continue
+		}
+		if _, ok := connectionFields[name]; ok {
+			continue
+		}
+
+		oldField := oldValue.Field(i).Interface()
+		newField := newValue.Field(i).Interface()
+		if !reflect.DeepEqual(oldField, newField) {
+			change.Fields[name] = FieldChange{Old: oldField, New: newField}
+		}
+	}
+
+	return change
+}
+
+// lookupEntryChanged 判断域名的查询相关覆盖项是否变化，仅标签或来源变化时不需要重新查询
+func lookupEntryChanged(oldEntry, newEntry DomainEntry) bool {
+	return oldEntry.Timeout != newEntry.Timeout ||
+		!reflect.DeepEqual(oldEntry.Providers, newEntry.Providers) ||
+		!reflect.DeepEqual(oldEntry.TLS, newEntry.TLS)
+}
+
+// IsEmpty 是否没有任何变化
+func (c ConfigChange) IsEmpty() bool {
+	return len(c.AddedDomains) == 0 && len(c.RemovedDomains) == 0 &&
+		len(c.ChangedDomains) == 0 && len(c.Fields) == 0
+}
+
+// HasField 指定业务字段是否发生变化
+func (c ConfigChange) HasField(name string) bool {
+	_, ok := c.Fields[name]
+	return ok
+}
+
+// RequiresFullCheck 是否有影响所有域名查询结果的字段变化
+func (c ConfigChange) RequiresFullCheck() bool {
+	for _, name := range lookupFields {
+		if c.HasField(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// DomainsToCheck 需要立即检查的域名（新增或查询相关覆盖项变化的域名）。
+// 仅标签变化的域名不重新查询，domain_info在采集时按新标签和缓存的检查结果输出
+func (c ConfigChange) DomainsToCheck() []string {
+	domains := make([]string, 0, len(c.AddedDomains)+len(c.LookupChanged))
+	domains = append(domains, c.AddedDomains...)
+	return append(domains, c.LookupChanged...)
+}
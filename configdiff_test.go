@@ -0,0 +1,205 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfig(t *testing.T) {
+	domains := func(names ...string) []DomainEntry {
+		entries := make([]DomainEntry, 0, len(names))
+		for _, name := range names {
+			entries = append(entries, DomainEntry{Name: name})
+		}
+		return entries
+	}
+
+	tests := []struct {
+		name            string
+		old             *Config
+		new             *Config
+		wantAdded       []string
+		wantRemoved     []string
+		wantChanged     []string
+		wantFields      []string
+		wantFullCheck   bool
+		wantDomainCheck []string
+	}{
+		{
+			name:            "初始配置视为全部新增",
+			new:             &Config{Domains: domains("b.com", "a.com")},
+			wantAdded:       []string{"a.com", "b.com"},
+			wantDomainCheck: []string{"a.com", "b.com"},
+		},
+		{
+			name: "无变化",
+			old:  &Config{Domains: domains("a.com"), CheckInterval: 3600},
+			new:  &Config{Domains: domains("a.com"), CheckInterval: 3600},
+		},
+		{
+			name:            "域名数量不变但内容替换",
+			old:             &Config{Domains: domains("a.com", "b.com")},
+			new:             &Config{Domains: domains("a.com", "c.com")},
+			wantAdded:       []string{"c.com"},
+			wantRemoved:     []string{"b.com"},
+			wantDomainCheck: []string{"c.com"},
+		},
+		{
+			name: "仅标签变化",
+			old: &Config{Domains: []DomainEntry{
+				{Name: "a.com", Labels: map[string]string{"team": "ops"}},
+				{Name: "b.com"},
+			}},
+			new: &Config{Domains: []DomainEntry{
+				{Name: "a.com", Labels: map[string]string{"team": "dev"}},
+				{Name: "b.com"},
+			}},
+			wantChanged: []string{"a.com"},
+		},
+		{
+			name: "查询相关覆盖项变化",
+			old: &Config{Domains: []DomainEntry{
+				{Name: "a.com", Labels: map[string]string{"team": "ops"}},
+				{Name: "b.com"},
+				{Name: "c.com"},
+				{Name: "d.com"},
+			}},
+			new: &Config{Domains: []DomainEntry{
+				{Name: "a.com", Labels: map[string]string{"team": "dev"}, Timeout: 10},
+				{Name: "b.com", Providers: []string{"whois"}},
+				{Name: "c.com", TLS: &DomainTLSConfig{Endpoints: []string{"www.c.com"}}},
+				{Name: "d.com", Source: "extra"},
+			}},
+			wantChanged:     []string{"a.com", "b.com", "c.com", "d.com"},
+			wantDomainCheck: []string{"a.com", "b.com", "c.com"},
+		},
+		{
+			name: "忽略连接配置字段",
+			old: &Config{
+				Domains:  domains("a.com"),
+				NacosUrl: "http://nacos-a:8848",
+				Username: "nacos",
+				Password: "old",
+				Consul:   ConsulSourceConfig{Address: "consul-a:8500"},
+			},
+			new: &Config{
+				Domains:  domains("a.com"),
+				NacosUrl: "http://nacos-b:8848",
+				Username: "admin",
+				Password: "new",
+				Consul:   ConsulSourceConfig{Address: "consul-b:8500"},
+			},
+		},
+		{
+			name:       "业务字段变化",
+			old:        &Config{CheckInterval: 3600, Concurrency: 5},
+			new:        &Config{CheckInterval: 1800, Concurrency: 5},
+			wantFields: []string{"check_interval"},
+		},
+		{
+			name:          "提供者变化需要重新检查全部域名",
+			old:           &Config{Providers: ProviderConfig{Default: []string{"whois"}}},
+			new:           &Config{Providers: ProviderConfig{Default: []string{"rdap", "whois"}}},
+			wantFields:    []string{"providers"},
+			wantFullCheck: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := DiffConfig(tt.old, tt.new)
+
+			if !equalStrings(change.AddedDomains, tt.wantAdded) {
+				t.Errorf("AddedDomains = %v, want %v", change.AddedDomains, tt.wantAdded)
+			}
+			if !equalStrings(change.RemovedDomains, tt.wantRemoved) {
+				t.Errorf("RemovedDomains = %v, want %v", change.RemovedDomains, tt.wantRemoved)
+			}
+			if !equalStrings(change.ChangedDomains, tt.wantChanged) {
+				t.Errorf("ChangedDomains = %v, want %v", change.ChangedDomains, tt.wantChanged)
+			}
+			if len(change.Fields) != len(tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", change.Fields, tt.wantFields)
+			}
+			for _, name := range tt.wantFields {
+				if !change.HasField(name) {
+					t.Errorf("Fields 缺少 %s: %v", name, change.Fields)
+				}
+			}
+			if change.RequiresFullCheck() != tt.wantFullCheck {
+				t.Errorf("RequiresFullCheck() = %v, want %v", change.RequiresFullCheck(), tt.wantFullCheck)
+			}
+			if !equalStrings(change.DomainsToCheck(), tt.wantDomainCheck) {
+				t.Errorf("DomainsToCheck() = %v, want %v", change.DomainsToCheck(), tt.wantDomainCheck)
+			}
+			wantEmpty := len(tt.wantAdded)+len(tt.wantRemoved)+len(tt.wantChanged)+len(tt.wantFields) == 0
+			if change.IsEmpty() != wantEmpty {
+				t.Errorf("IsEmpty() = %v, want %v", change.IsEmpty(), wantEmpty)
+			}
+		})
+	}
+}
+
+func TestCopyConnectionFields(t *testing.T) {
+	local := &Config{
+		NacosUrl:      "http://nacos:8848",
+		Password:      "secret",
+		SkipSSLVerify: true,
+		Etcd:          EtcdSourceConfig{Endpoints: []string{"etcd:2379"}},
+		CheckInterval: 3600,
+	}
+	remote := &Config{
+		Domains:       []DomainEntry{{Name: "example.com"}},
+		NacosUrl:      "http://other:8848",
+		CheckInterval: 600,
+	}
+
+	copyConnectionFields(remote, local)
+
+	if remote.NacosUrl != local.NacosUrl || remote.Password != local.Password || !remote.SkipSSLVerify {
+		t.Errorf("连接配置未从本地配置复制: %+v", remote)
+	}
+	if !reflect.DeepEqual(remote.Etcd, local.Etcd) {
+		t.Errorf("Etcd = %+v, want %+v", remote.Etcd, local.Etcd)
+	}
+	// 业务字段保留配置中心的值
+	if remote.CheckInterval != 600 || len(remote.Domains) != 1 {
+		t.Errorf("业务字段被覆盖: CheckInterval=%d, Domains=%v", remote.CheckInterval, remote.Domains)
+	}
+}
+
+func TestConfigHolderNotifyMergesPending(t *testing.T) {
+	first := &Config{Domains: []DomainEntry{{Name: "a.com"}, {Name: "b.com"}}}
+	second := &Config{Domains: []DomainEntry{{Name: "a.com"}}}
+	third := &Config{Domains: []DomainEntry{{Name: "a.com"}, {Name: "c.com"}}}
+
+	holder := newConfigHolder(first)
+	holder.notify(NewConfigUpdate(first, second))
+	holder.notify(NewConfigUpdate(second, third))
+
+	update := <-holder.updateChan
+	select {
+	case extra := <-holder.updateChan:
+		t.Fatalf("收到多余的配置更新通知: %+v", extra)
+	default:
+	}
+
+	if update.Previous != first || update.Config != third {
+		t.Fatalf("合并后的通知应基于第一次的旧配置和最新配置")
+	}
+	// 第一次通知中删除b.com的变化不能丢失
+	if !equalStrings(update.Change.RemovedDomains, []string{"b.com"}) {
+		t.Errorf("RemovedDomains = %v, want [b.com]", update.Change.RemovedDomains)
+	}
+	if !equalStrings(update.Change.AddedDomains, []string{"c.com"}) {
+		t.Errorf("AddedDomains = %v, want [c.com]", update.Change.AddedDomains)
+	}
+}
+
+// equalStrings 比较字符串切片，nil与空切片视为相等
+func equalStrings(a, b []string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ConfigSource 动态配置来源（配置中心或本地文件）
+type ConfigSource interface {
+	// Name 配置源名称，如 nacos、consul
+	Name() string
+	// GetConfig 获取当前配置（初始加载失败时为本地配置）
+	GetConfig() *Config
+	// GetUpdateChannel 获取配置更新通道
+	GetUpdateChannel() <-chan *ConfigUpdate
+	// Close 停止监听配置变化
+	Close()
+
+	// 配置重载指标
+	prometheus.Collector
+}
+
+// configSourcePollInterval 配置中心监听失败时回退到定期拉取的间隔
+const configSourcePollInterval = 10 * time.Second
+
+// ConfigSourceType 获取配置源类型，未指定时启用了Nacos则使用nacos，指定了配置文件则使用file
+func (c *Config) ConfigSourceType() string {
+	if c.ConfigSource != "" {
+		return strings.ToLower(c.ConfigSource)
+	}
+	if c.IsNacosEnabled() {
+		return "nacos"
+	}
+	if c.ConfigFile != "" {
+		return "file"
+	}
+	return ""
+}
+
+// NewConfigSource 根据本地配置创建配置源，未配置任何配置源时返回nil
+func NewConfigSource(localConfig *Config) (ConfigSource, error) {
+	switch sourceType := localConfig.ConfigSourceType(); sourceType {
+	case "":
+		return nil, nil
+	case "nacos":
+		if !localConfig.IsNacosEnabled() {
+			return nil, fmt.Errorf("配置源为nacos但未配置nacos_url")
+		}
+		return asConfigSource(NewNacosConfigManager(localConfig))
+	case "consul":
+		return asConfigSource(NewConsulConfigSource(localConfig))
+	case "etcd":
+		return asConfigSource(NewEtcdConfigSource(localConfig))
+	case "apollo":
+		return asConfigSource(NewApolloConfigSource(localConfig))
+	case "file":
+		if localConfig.ConfigFile == "" {
+			return nil, fmt.Errorf("配置源为file但未通过-config指定配置文件")
+		}
+		return NewFileConfigWatcher(localConfig), nil
+	default:
+		return nil, fmt.Errorf("未知的配置源: %s", sourceType)
+	}
+}
+
+// asConfigSource 转换为接口类型，创建失败时返回nil接口而不是nil指针
+func asConfigSource[T ConfigSource](source T, err error) (ConfigSource, error) {
+	if err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// configHolder 配置源的当前配置和更新通知，供各配置源嵌入
+type configHolder struct {
+	config      *Config
+	configMutex sync.RWMutex
+	updateChan  chan *ConfigUpdate
+	rejectedMD5 string // 最近一次被拒绝的配置内容的MD5，避免重复记录同一份无效配置
+
+	reloadSuccess  prometheus.Gauge
+	lastReload     prometheus.Gauge
+	reloadFailures *prometheus.CounterVec
+}
+
+// newConfigHolder 以本地配置作为初始配置创建
+func newConfigHolder(localConfig *Config) configHolder {
+	reloadSuccess := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "domain_exporter_config_reload_success",
+			Help: "最近一次配置重载是否成功 (1=已应用, 0=已拒绝，继续使用上次有效的配置)",
+		},
+	)
+	lastReload := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "domain_exporter_config_last_reload_timestamp",
+			Help: "最近一次成功应用配置的时间戳",
+		},
+	)
+
+	// 启动时的本地配置已通过校验
+	reloadSuccess.Set(1)
+	lastReload.SetToCurrentTime()
+
+	return configHolder{
+		config:        localConfig,
+		updateChan:    make(chan *ConfigUpdate, 1),
+		reloadSuccess: reloadSuccess,
+		lastReload:    lastReload,
+		reloadFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "domain_exporter_config_reload_failures_total",
+				Help: "因配置无效而被拒绝的配置重载次数",
+			},
+			[]string{"reason"},
+		),
+	}
+}
+
+// GetConfig 获取当前配置
+func (h *configHolder) GetConfig() *Config {
+	h.configMutex.RLock()
+	defer h.configMutex.RUnlock()
+	return h.config
+}
+
+// GetUpdateChannel 获取配置更新通道
+func (h *configHolder) GetUpdateChannel() <-chan *ConfigUpdate {
+	return h.updateChan
+}
+
+// applyContent 严格解析并校验配置中心返回的YAML内容，保留本地连接配置后替换当前配置；
+// 内容无效时保留上次有效的配置
+func (h *configHolder) applyContent(source, content string) error {
+	remoteConfig, err := ParseConfig([]byte(content))
+	if err != nil {
+		return h.reject(source, content, "invalid", fmt.Errorf("配置校验失败: %w", err))
+	}
+
+	// 保留原始的连接配置
+	copyConnectionFields(remoteConfig, h.GetConfig())
+
+	// 应用默认值
+	applyDefaults(remoteConfig)
+
+	return h.apply(source, content, remoteConfig)
+}
+
+// apply 替换为已通过校验的新配置；新配置会清空全部域名时拒绝，
+// 避免误推送的空配置删除所有域名的指标（可通过allow_empty_domains显式允许）
+func (h *configHolder) apply(source, content string, newConfig *Config) error {
+	if len(newConfig.Domains) == 0 && len(newConfig.DomainSources) == 0 && !newConfig.AllowEmptyDomains &&
+		len(h.GetConfig().Domains) > 0 {
+		return h.reject(source, content, "empty_domains", fmt.Errorf("新配置的域名列表为空，如需清空请设置allow_empty_domains: true"))
+	}
+
+	h.configMutex.Lock()
+	h.rejectedMD5 = ""
+	h.configMutex.Unlock()
+
+	h.replace(source, newConfig)
+	h.reloadSuccess.Set(1)
+	h.lastReload.SetToCurrentTime()
+	return nil
+}
+
+// reject 拒绝无效的配置并记录到配置重载指标，同一份内容只记录一次
+func (h *configHolder) reject(source, content, reason string, err error) error {
+	md5 := contentMD5(content)
+	h.configMutex.Lock()
+	repeated := h.rejectedMD5 == md5
+	h.rejectedMD5 = md5
+	h.configMutex.Unlock()
+
+	if !repeated {
+		slog.Error("配置无效，已拒绝并继续使用上次有效的配置", "source", source, "reason", reason, "error", err)
+		h.reloadSuccess.Set(0)
+		h.reloadFailures.WithLabelValues(reason).Inc()
+	}
+	return err
+}
+
+// replace 替换当前配置，业务配置有变化时发送更新通知
+func (h *configHolder) replace(source string, newConfig *Config) {
+	h.configMutex.Lock()
+	oldConfig := h.config
+	h.config = newConfig
+	h.configMutex.Unlock()
+
+	// 完整比较域名集合和所有业务字段
+	update := NewConfigUpdate(oldConfig, newConfig)
+	change := update.Change
+	if change.IsEmpty() {
+		slog.Debug("配置已重新加载，业务配置未变化", "source", source)
+		return
+	}
+
+	slog.Info("配置已更新",
+		"source", source,
+		"domain_count", len(newConfig.Domains),
+		"added_domains", len(change.AddedDomains),
+		"removed_domains", len(change.RemovedDomains),
+		"changed_domains", len(change.ChangedDomains),
+		"changed_fields", len(change.Fields))
+
+	h.notify(update)
+}
+
+// notify 发送配置更新通知，通道已满时将未消费的更新与新更新合并
+func (h *configHolder) notify(update *ConfigUpdate) {
+	for {
+		select {
+		case h.updateChan <- update:
+			slog.Debug("已发送配置更新通知")
+			return
+		default:
+		}
+
+		// 取出未被消费的旧通知，基于其之前的配置重新计算变更集，避免丢失变化
+		select {
+		case pending := <-h.updateChan:
+			slog.Debug("合并未消费的配置更新通知")
+			update = NewConfigUpdate(pending.Previous, update.Config)
+		default:
+		}
+	}
+}
+
+// Describe 实现prometheus.Collector接口
+func (h *configHolder) Describe(ch chan<- *prometheus.Desc) {
+	h.reloadSuccess.Describe(ch)
+	h.lastReload.Describe(ch)
+	h.reloadFailures.Describe(ch)
+}
+
+// Collect 实现prometheus.Collector接口
+func (h *configHolder) Collect(ch chan<- prometheus.Metric) {
+	h.reloadSuccess.Collect(ch)
+	h.lastReload.Collect(ch)
+	h.reloadFailures.Collect(ch)
+}
+
+// newSourceHTTPClient 创建访问配置中心的HTTP客户端
+func newSourceHTTPClient(timeout time.Duration, skipSSLVerify bool) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	if skipSSLVerify {
+		slog.Warn("已禁用SSL证书验证，仅适用于开发/测试环境")
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+	return client
+}
+
+// sleepContext 等待指定时间，ctx取消时立即返回false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
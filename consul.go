@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// consulWaitTime 阻塞查询的最长挂起时间
+const consulWaitTime = 5 * time.Minute
+
+// ConsulSourceConfig Consul KV配置源连接配置
+type ConsulSourceConfig struct {
+	Address    string `yaml:"address"`    // Consul地址，如 http://127.0.0.1:8500
+	Token      string `yaml:"token"`      // ACL Token（可选）
+	Key        string `yaml:"key"`        // 存放YAML配置的KV键，默认 domain-exporter
+	Datacenter string `yaml:"datacenter"` // 数据中心（可选）
+}
+
+// ConsulConfigSource 基于Consul KV阻塞查询的配置源
+type ConsulConfigSource struct {
+	configHolder
+	settings   ConsulSourceConfig
+	httpClient *http.Client
+	index      uint64 // 最近一次查询返回的X-Consul-Index
+	contentMD5 string // 当前配置内容的MD5
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// NewConsulConfigSource 创建Consul KV配置源
+func NewConsulConfigSource(localConfig *Config) (*ConsulConfigSource, error) {
+	settings := localConfig.Consul
+	if settings.Address == "" {
+		return nil, fmt.Errorf("未配置Consul地址（consul.address）")
+	}
+	settings.Address = strings.TrimRight(settings.Address, "/")
+
+	slog.Info("创建Consul配置源", "address", settings.Address, "key", settings.Key, "datacenter", settings.Datacenter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	source := &ConsulConfigSource{
+		configHolder: newConfigHolder(localConfig),
+		settings:     settings,
+		// Consul会在挂起时间上增加最多1/16的随机抖动
+		httpClient: newSourceHTTPClient(consulWaitTime+consulWaitTime/16+15*time.Second, localConfig.SkipSSLVerify),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	// 初始加载配置
+	if err := source.load(); err != nil {
+		slog.Warn("初始配置加载失败，将使用本地配置", "source", "consul", "error", err)
+	}
+
+	go source.watch()
+
+	return source, nil
+}
+
+// Name 配置源名称
+func (s *ConsulConfigSource) Name() string {
+	return "consul"
+}
+
+// watch 通过阻塞查询监听KV变化，失败时等待后重试
+func (s *ConsulConfigSource) watch() {
+	slog.Info("启动Consul配置监听", "wait", consulWaitTime)
+
+	for {
+		err := s.load()
+		if s.ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			slog.Debug("Consul配置查询失败，稍后重试", "error", err)
+			if !sleepContext(s.ctx, configSourcePollInterval) {
+				break
+			}
+		}
+	}
+
+	slog.Info("停止Consul配置监听")
+}
+
+// load 查询KV，已有索引时作为阻塞查询在变化或超时后返回
+func (s *ConsulConfigSource) load() error {
+	query := url.Values{}
+	if s.index > 0 {
+		query.Set("index", strconv.FormatUint(s.index, 10))
+		query.Set("wait", consulWaitTime.String())
+	}
+	if s.settings.Datacenter != "" {
+		query.Set("dc", s.settings.Datacenter)
+	}
+	kvURL := fmt.Sprintf("%s/v1/kv/%s?%s", s.settings.Address, strings.TrimLeft(s.settings.Key, "/"), query.Encode())
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, kvURL, nil)
+	if err != nil {
+		return fmt.Errorf("创建查询请求失败: %w", err)
+	}
+	if s.settings.Token != "" {
+		req.Header.Set("X-Consul-Token", s.settings.Token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("查询请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取查询响应失败: %w", err)
+	}
+
+	// 索引变小（如Consul重建）时需要重置，否则阻塞查询会一直挂起
+	index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if index < s.index {
+		index = 0
+	}
+	s.index = index
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("配置不存在: %s", s.settings.Key)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("查询失败: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var entries []struct {
+		Value []byte `json:"Value"` // base64编码，由encoding/json自动解码
+	}
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return fmt.Errorf("解析查询响应失败: %w", err)
+	}
+	if len(entries) == 0 || len(entries[0].Value) == 0 {
+		return fmt.Errorf("配置不存在或为空")
+	}
+
+	content := string(entries[0].Value)
+	if md5 := contentMD5(content); md5 != s.contentMD5 {
+		if err := s.applyContent("consul", content); err != nil {
+			return err
+		}
+		s.contentMD5 = md5
+	}
+	return nil
+}
+
+// Close 停止监听
+func (s *ConsulConfigSource) Close() {
+	s.cancel()
+	slog.Info("Consul配置源已关闭")
+}
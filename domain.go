@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DomainEntry 域名配置，支持纯字符串和对象两种写法：
+//
+//	domains:
+//	  - example.com
+//	  - name: pay.example.com
+//	    labels: {team: payments, env: prod}
+//	    timeout: 60
+//	    providers: [whois]
+//	    tls: {endpoints: ["pay.example.com:8443"]}
+type DomainEntry struct {
+	Name      string            `yaml:"name" json:"name"`
+	Labels    map[string]string `yaml:"labels" json:"labels,omitempty"`       // 附加到domain_info指标的标签，用于告警路由
+	Timeout   int               `yaml:"timeout" json:"timeout,omitempty"`     // 覆盖全局查询超时（秒）
+	Providers []string          `yaml:"providers" json:"providers,omitempty"` // 覆盖提供者链
+	TLS       *DomainTLSConfig  `yaml:"tls" json:"tls,omitempty"`             // TLS证书检查配置
+
+	Source string `yaml:"-" json:"source,omitempty"` // 域名来源名称（多个来源时以逗号分隔），合并域名来源时设置
+}
+
+// UnmarshalYAML 同时支持字符串和对象写法
+func (d *DomainEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*d = DomainEntry{Name: strings.TrimSpace(name)}
+		return nil
+	}
+
+	type plain DomainEntry
+	var entry plain
+	if err := unmarshal(&entry); err != nil {
+		return err
+	}
+	entry.Name = strings.TrimSpace(entry.Name)
+	if entry.Name == "" {
+		return fmt.Errorf("域名配置缺少name字段")
+	}
+	*d = DomainEntry(entry)
+	return nil
+}
+
+// DomainNames 获取所有域名名称
+func (c *Config) DomainNames() []string {
+	names := make([]string, 0, len(c.Domains))
+	for _, entry := range c.Domains {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+// HasDomain 判断域名是否在配置中
+func (c *Config) HasDomain(name string) bool {
+	for _, entry := range c.Domains {
+		if entry.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LookupDomainName 不区分大小写地查找配置中的域名，返回配置中的写法
+func (c *Config) LookupDomainName(name string) (string, bool) {
+	for _, entry := range c.Domains {
+		if strings.EqualFold(entry.Name, name) {
+			return entry.Name, true
+		}
+	}
+	return "", false
+}
+
+// DomainEntry 按名称获取域名配置，未配置时返回仅包含名称的默认配置
+func (c *Config) DomainEntry(name string) DomainEntry {
+	for _, entry := range c.Domains {
+		if entry.Name == name {
+			return entry
+		}
+	}
+	return DomainEntry{Name: name}
+}
+
+// forDomain 返回应用了域名级覆盖项的配置副本，未配置覆盖项时返回原配置
+func (c *Config) forDomain(entry DomainEntry) *Config {
+	if entry.Timeout <= 0 && len(entry.Providers) == 0 {
+		return c
+	}
+
+	domainConfig := *c
+	if entry.Timeout > 0 {
+		domainConfig.Timeout = entry.Timeout
+	}
+	if len(entry.Providers) > 0 {
+		domainConfig.Providers = ProviderConfig{Default: entry.Providers}
+	}
+	return &domainConfig
+}
+
+// invalidLabelChars 匹配Prometheus标签名中不允许的字符
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// sanitizeLabelName 将自定义标签名转换为合法的Prometheus标签名
+func sanitizeLabelName(name string) string {
+	name = invalidLabelChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	// 避免与内置标签冲突
+	if builtinInfoLabels[name] || strings.HasPrefix(name, "__") {
+		name = "label_" + strings.TrimLeft(name, "_")
+	}
+	return name
+}
+
+// builtinInfoLabels domain_info的内置标签，自定义标签同名时加label_前缀
+var builtinInfoLabels = map[string]bool{
+	"domain":            true,
+	"source":            true,
+	"registrar":         true,
+	"registrar_iana_id": true,
+	"whois_server":      true,
+	"method":            true,
+}
+
+// domainLabelNames 汇总所有域名的自定义标签名（已排序、已转换为合法标签名）
+func domainLabelNames(entries []DomainEntry) []string {
+	seen := make(map[string]struct{})
+	for _, entry := range entries {
+		for key := range entry.Labels {
+			seen[sanitizeLabelName(key)] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// domainLabelValues 按标签名顺序获取域名的自定义标签值，未设置的标签为空字符串
+func domainLabelValues(entry DomainEntry, names []string) []string {
+	sanitized := make(map[string]string, len(entry.Labels))
+	for key, value := range entry.Labels {
+		sanitized[sanitizeLabelName(key)] = value
+	}
+
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = sanitized[name]
+	}
+	return values
+}
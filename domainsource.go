@@ -0,0 +1,522 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v2"
+)
+
+// configDomainSource domains字段中直接配置的域名的来源名称
+const configDomainSource = "config"
+
+// defaultDomainSourceRefresh 域名来源默认刷新间隔（秒）
+const defaultDomainSourceRefresh = 300
+
+// DomainSourceConfig 域名来源配置，多个来源的域名与domains字段合并去重：
+//
+//	domain_sources:
+//	  - {name: git, type: file, paths: ["/etc/domains/*.yml"], labels: {owner: sre}}
+//	  - {name: dns, type: http, url: "https://dns.example.com/zones", format: text}
+//	  - {name: extra, type: nacos, data_id: extra-domains}
+//	  - {name: legacy, type: static, domains: [old.example.com]}
+type DomainSourceConfig struct {
+	Name            string            `yaml:"name"`             // 来源名称，作为domain_info的source标签
+	Type            string            `yaml:"type"`             // static, file, http, nacos
+	Labels          map[string]string `yaml:"labels"`           // 附加到该来源所有域名的标签（域名自身标签优先）
+	RefreshInterval int               `yaml:"refresh_interval"` // 刷新间隔（秒），默认300
+	Format          string            `yaml:"format"`           // 内容格式: yaml（默认，兼容JSON）、text（每行一个域名）
+	AllowEmpty      bool              `yaml:"allow_empty"`      // 允许返回空列表，默认视为刷新失败，避免误删该来源的所有域名
+
+	Domains []DomainEntry     `yaml:"domains"` // static: 域名列表
+	Paths   []string          `yaml:"paths"`   // file: 文件路径，支持glob
+	URL     string            `yaml:"url"`     // http: 返回域名列表的地址
+	Headers map[string]string `yaml:"headers"` // http: 附加请求头
+	DataId  string            `yaml:"data_id"` // nacos: 存放域名列表的dataId（使用本地Nacos连接配置）
+	Group   string            `yaml:"group"`   // nacos: 分组，默认DEFAULT_GROUP
+}
+
+// DomainSourceManager 定期刷新域名来源，并与配置中的域名合并
+type DomainSourceManager struct {
+	mutex    sync.RWMutex
+	sources  []DomainSourceConfig
+	results  map[string][]DomainEntry  // 各来源最近一次成功刷新的域名
+	fetchers map[string]*sourceFetcher // 各来源的获取函数，来源配置未变化时复用
+	onChange func()                    // 任一来源的域名列表变化时调用
+	cancel   context.CancelFunc
+
+	refreshFailures *prometheus.CounterVec
+	sourceUp        *prometheus.GaugeVec
+	sourceDomains   *prometheus.GaugeVec
+	lastSuccess     *prometheus.GaugeVec
+}
+
+// NewDomainSourceManager 创建域名来源管理器
+func NewDomainSourceManager(onChange func()) *DomainSourceManager {
+	return &DomainSourceManager{
+		results:  make(map[string][]DomainEntry),
+		fetchers: make(map[string]*sourceFetcher),
+		onChange: onChange,
+		refreshFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "domain_source_refresh_failures_total",
+				Help: "域名来源刷新失败的次数",
+			},
+			[]string{"source"},
+		),
+		sourceUp: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_source_up",
+				Help: "域名来源最近一次刷新是否成功 (1=成功, 0=失败，继续使用上次成功的结果)",
+			},
+			[]string{"source"},
+		),
+		sourceDomains: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_source_domains",
+				Help: "域名来源提供的域名数量",
+			},
+			[]string{"source"},
+		),
+		lastSuccess: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_source_last_success_timestamp",
+				Help: "域名来源最近一次刷新成功的时间戳",
+			},
+			[]string{"source"},
+		),
+	}
+}
+
+// Update 应用新的来源配置：来源定义变化时同步刷新一次并重新启动定期刷新
+func (m *DomainSourceManager) Update(config *Config) {
+	sources := normalizeDomainSources(config.DomainSources)
+
+	m.mutex.Lock()
+	if reflect.DeepEqual(m.sources, sources) {
+		m.mutex.Unlock()
+		return
+	}
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+
+	// 清理已删除来源的结果和指标
+	active := make(map[string]struct{}, len(sources))
+	for _, source := range sources {
+		active[source.Name] = struct{}{}
+	}
+	for _, source := range m.sources {
+		if _, ok := active[source.Name]; !ok {
+			delete(m.results, source.Name)
+			m.deleteMetrics(source.Name)
+		}
+	}
+	m.sources = sources
+	previousFetchers := m.fetchers
+	m.fetchers = make(map[string]*sourceFetcher, len(sources))
+	m.mutex.Unlock()
+
+	// 来源配置未变化时复用获取函数（如Nacos客户端），其余的关闭
+	defer func() {
+		for _, fetcher := range previousFetchers {
+			fetcher.close()
+		}
+	}()
+
+	if len(sources) == 0 {
+		return
+	}
+	slog.Info("域名来源配置已更新", "sources", len(sources))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mutex.Lock()
+	m.cancel = cancel
+	m.mutex.Unlock()
+
+	// 首次同步刷新，保证启动或配置更新后的检查包含所有来源的域名
+	for _, source := range sources {
+		if source.Type == "static" {
+			m.sourceUp.WithLabelValues(source.Name).Set(1)
+			m.sourceDomains.WithLabelValues(source.Name).Set(float64(len(source.Domains)))
+			continue
+		}
+		fetcher, ok := previousFetchers[source.Name]
+		if ok && reflect.DeepEqual(fetcher.source, source) {
+			delete(previousFetchers, source.Name)
+		} else {
+			fetch, closeFetcher, err := newDomainFetcher(source, config)
+			if err != nil {
+				slog.Error("域名来源配置无效", "source", source.Name, "error", err)
+				m.recordFailure(source.Name)
+				continue
+			}
+			fetcher = &sourceFetcher{source: source, fetch: fetch, close: closeFetcher}
+		}
+		m.mutex.Lock()
+		m.fetchers[source.Name] = fetcher
+		m.mutex.Unlock()
+
+		m.refresh(ctx, source, fetcher.fetch)
+		go m.run(ctx, source, fetcher.fetch)
+	}
+}
+
+// Close 停止定期刷新并释放各来源的客户端
+func (m *DomainSourceManager) Close() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	for name, fetcher := range m.fetchers {
+		fetcher.close()
+		delete(m.fetchers, name)
+	}
+}
+
+// run 按刷新间隔定期刷新单个来源
+func (m *DomainSourceManager) run(ctx context.Context, source DomainSourceConfig, fetcher domainFetcher) {
+	ticker := time.NewTicker(time.Duration(source.RefreshInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if m.refresh(ctx, source, fetcher) {
+				m.onChange()
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refresh 刷新单个来源，返回域名列表是否变化；失败时保留上次成功的结果
+func (m *DomainSourceManager) refresh(ctx context.Context, source DomainSourceConfig, fetcher domainFetcher) bool {
+	entries, err := fetcher(ctx)
+	if ctx.Err() != nil {
+		return false
+	}
+	if err == nil {
+		entries, err = normalizeSourceDomains(source, entries)
+	}
+	if err != nil {
+		slog.Warn("域名来源刷新失败，继续使用上次成功的结果", "source", source.Name, "error", err)
+		m.recordFailure(source.Name)
+		return false
+	}
+
+	now := time.Now()
+	m.sourceUp.WithLabelValues(source.Name).Set(1)
+	m.sourceDomains.WithLabelValues(source.Name).Set(float64(len(entries)))
+	m.lastSuccess.WithLabelValues(source.Name).Set(float64(now.Unix()))
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	previous, ok := m.results[source.Name]
+	m.results[source.Name] = entries
+	changed := !ok || !reflect.DeepEqual(previous, entries)
+	if changed {
+		slog.Info("域名来源已刷新", "source", source.Name, "domain_count", len(entries))
+	}
+	return changed
+}
+
+// normalizeSourceDomains 统一域名写法并忽略无效的域名；没有有效域名时返回错误（设置allow_empty且列表本身为空时除外）
+func normalizeSourceDomains(source DomainSourceConfig, entries []DomainEntry) ([]DomainEntry, error) {
+	valid := make([]DomainEntry, 0, len(entries))
+	var invalid []string
+	for _, entry := range entries {
+		name := strings.ToLower(strings.Trim(strings.TrimSpace(entry.Name), "."))
+		if err := validateDomainName(name); err != nil {
+			invalid = append(invalid, entry.Name)
+			continue
+		}
+		entry.Name = name
+		valid = append(valid, entry)
+	}
+
+	if len(invalid) > 0 {
+		examples := invalid
+		if len(examples) > 5 {
+			examples = examples[:5]
+		}
+		slog.Warn("域名来源包含无效的域名，已忽略", "source", source.Name, "invalid_count", len(invalid), "examples", examples)
+	}
+
+	if len(valid) == 0 {
+		if len(invalid) > 0 {
+			return nil, fmt.Errorf("域名来源没有有效的域名（%d个无效条目）", len(invalid))
+		}
+		if !source.AllowEmpty {
+			return nil, fmt.Errorf("域名来源返回空列表（如需允许请设置allow_empty: true）")
+		}
+	}
+	return valid, nil
+}
+
+// recordFailure 记录来源刷新失败
+func (m *DomainSourceManager) recordFailure(name string) {
+	m.refreshFailures.WithLabelValues(name).Inc()
+	m.sourceUp.WithLabelValues(name).Set(0)
+}
+
+// deleteMetrics 删除来源的指标
+func (m *DomainSourceManager) deleteMetrics(name string) {
+	m.refreshFailures.DeleteLabelValues(name)
+	m.sourceUp.DeleteLabelValues(name)
+	m.sourceDomains.DeleteLabelValues(name)
+	m.lastSuccess.DeleteLabelValues(name)
+}
+
+// Merge 返回合并了所有来源域名的配置副本，同一域名在多个来源中出现时合并为一项
+func (m *DomainSourceManager) Merge(config *Config) *Config {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	merged := *config
+	merged.Domains = nil
+	index := make(map[string]int)
+
+	add := func(sourceName string, labels map[string]string, entries []DomainEntry) {
+		for _, entry := range entries {
+			key := strings.ToLower(entry.Name)
+			if i, ok := index[key]; ok {
+				// 先出现的配置优先，后续来源只补充缺失的标签并记录来源
+				existing := &merged.Domains[i]
+				existing.Labels = mergeLabels(existing.Labels, entry.Labels, labels)
+				if !containsString(strings.Split(existing.Source, ","), sourceName) {
+					existing.Source += "," + sourceName
+				}
+				continue
+			}
+			entry.Labels = mergeLabels(entry.Labels, labels)
+			entry.Source = sourceName
+			index[key] = len(merged.Domains)
+			merged.Domains = append(merged.Domains, entry)
+		}
+	}
+
+	add(configDomainSource, nil, config.Domains)
+	for _, source := range m.sources {
+		if source.Type == "static" {
+			add(source.Name, source.Labels, source.Domains)
+		} else if entries, ok := m.results[source.Name]; ok {
+			add(source.Name, source.Labels, entries)
+		}
+	}
+	return &merged
+}
+
+// mergeLabels 合并标签，靠前的参数优先；都为空时返回nil
+func mergeLabels(labelSets ...map[string]string) map[string]string {
+	var merged map[string]string
+	for i := len(labelSets) - 1; i >= 0; i-- {
+		for key, value := range labelSets[i] {
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// normalizeDomainSources 补全来源的默认值，并为未命名的来源生成名称
+func normalizeDomainSources(sources []DomainSourceConfig) []DomainSourceConfig {
+	normalized := make([]DomainSourceConfig, 0, len(sources))
+	for i, source := range sources {
+		source.Type = strings.ToLower(source.Type)
+		if source.Name == "" {
+			source.Name = fmt.Sprintf("%s-%d", source.Type, i)
+		}
+		if source.RefreshInterval <= 0 {
+			source.RefreshInterval = defaultDomainSourceRefresh
+		}
+		normalized = append(normalized, source)
+	}
+	return normalized
+}
+
+// Describe 实现prometheus.Collector接口
+func (m *DomainSourceManager) Describe(ch chan<- *prometheus.Desc) {
+	m.refreshFailures.Describe(ch)
+	m.sourceUp.Describe(ch)
+	m.sourceDomains.Describe(ch)
+	m.lastSuccess.Describe(ch)
+}
+
+// Collect 实现prometheus.Collector接口
+func (m *DomainSourceManager) Collect(ch chan<- prometheus.Metric) {
+	m.refreshFailures.Collect(ch)
+	m.sourceUp.Collect(ch)
+	m.sourceDomains.Collect(ch)
+	m.lastSuccess.Collect(ch)
+}
+
+// domainFetcher 获取单个来源当前的域名列表
+type domainFetcher func(ctx context.Context) ([]DomainEntry, error)
+
+// sourceFetcher 来源的获取函数及创建时的来源配置，close释放获取函数持有的客户端
+type sourceFetcher struct {
+	source DomainSourceConfig
+	fetch  domainFetcher
+	close  func()
+}
+
+// newDomainFetcher 根据来源类型创建获取函数，返回的关闭函数在来源删除或配置变化时调用
+func newDomainFetcher(source DomainSourceConfig, config *Config) (domainFetcher, func(), error) {
+	switch source.Type {
+	case "file":
+		if len(source.Paths) == 0 {
+			return nil, nil, fmt.Errorf("file类型的域名来源缺少paths")
+		}
+		return func(ctx context.Context) ([]DomainEntry, error) {
+			return fetchDomainFiles(source)
+		}, func() {}, nil
+	case "http":
+		if source.URL == "" {
+			return nil, nil, fmt.Errorf("http类型的域名来源缺少url")
+		}
+		client := &http.Client{Timeout: 30 * time.Second}
+		return func(ctx context.Context) ([]DomainEntry, error) {
+			return fetchDomainURL(ctx, client, source)
+		}, client.CloseIdleConnections, nil
+	case "nacos":
+		if !config.IsNacosEnabled() {
+			return nil, nil, fmt.Errorf("nacos类型的域名来源需要配置nacos_url")
+		}
+		if source.DataId == "" {
+			return nil, nil, fmt.Errorf("nacos类型的域名来源缺少data_id")
+		}
+		nacosConfig := *config
+		nacosConfig.DataId = source.DataId
+		nacosConfig.Group = source.Group
+		if nacosConfig.Group == "" {
+			nacosConfig.Group = "DEFAULT_GROUP"
+		}
+		client := newNacosClient(&nacosConfig)
+		return func(ctx context.Context) ([]DomainEntry, error) {
+			content, err := client.fetchContent()
+			if err != nil {
+				return nil, err
+			}
+			return parseDomainList([]byte(content), source.Format)
+		}, client.cancel, nil
+	default:
+		return nil, nil, fmt.Errorf("未知的域名来源类型: %s", source.Type)
+	}
+}
+
+// fetchDomainFiles 读取匹配的所有文件并合并
+func fetchDomainFiles(source DomainSourceConfig) ([]DomainEntry, error) {
+	var files []string
+	for _, pattern := range source.Paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("无效的文件路径 %s: %w", pattern, err)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("没有匹配的文件: %v", source.Paths)
+	}
+	sort.Strings(files)
+
+	var entries []DomainEntry
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取域名文件失败: %w", err)
+		}
+		format := source.Format
+		if format == "" && strings.HasSuffix(strings.ToLower(file), ".txt") {
+			format = "text"
+		}
+		fileEntries, err := parseDomainList(data, format)
+		if err != nil {
+			return nil, fmt.Errorf("解析域名文件 %s 失败: %w", file, err)
+		}
+		entries = append(entries, fileEntries...)
+	}
+	return entries, nil
+}
+
+// fetchDomainURL 请求HTTP地址获取域名列表
+func fetchDomainURL(ctx context.Context, client *http.Client, source DomainSourceConfig) ([]DomainEntry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	for key, value := range source.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求域名列表失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取域名列表失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("请求域名列表失败: HTTP %d", resp.StatusCode)
+	}
+
+	// 部分服务以text/plain返回JSON，内容以 [ 或 { 开头时仍按JSON解析
+	format := source.Format
+	trimmed := strings.TrimSpace(string(body))
+	if format == "" && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") &&
+		!strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "{") {
+		format = "text"
+	}
+	return parseDomainList(body, format)
+}
+
+// parseDomainList 解析域名列表，yaml格式（兼容JSON）支持列表或包含domains字段的对象，text格式每行一个域名
+func parseDomainList(data []byte, format string) ([]DomainEntry, error) {
+	if strings.ToLower(format) == "text" {
+		var entries []DomainEntry
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			entries = append(entries, DomainEntry{Name: line})
+		}
+		return entries, scanner.Err()
+	}
+
+	var entries []DomainEntry
+	if err := yaml.Unmarshal(data, &entries); err == nil {
+		return entries, nil
+	}
+
+	var wrapped struct {
+		Domains []DomainEntry `yaml:"domains"`
+	}
+	if err := yaml.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("解析域名列表失败: %w", err)
+	}
+	return wrapped.Domains, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDomainSourceManagerReusesFetchers(t *testing.T) {
+	fake := &fakeNacos{version: "2.3.2", v1Login: true, v2: func() (int, any) {
+		return http.StatusOK, map[string]any{"code": 0, "data": "- a.example.com\n- b.example.com\n"}
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	versionProbes := func() int {
+		fake.mutex.Lock()
+		defer fake.mutex.Unlock()
+		count := 0
+		for _, request := range fake.requests {
+			if strings.HasPrefix(request, "/nacos/v1/console/server/state?") {
+				count++
+			}
+		}
+		return count
+	}
+
+	nacosSource := DomainSourceConfig{Name: "extra", Type: "nacos", DataId: "extra-domains"}
+	staticSource := DomainSourceConfig{Name: "legacy", Type: "static", Domains: []DomainEntry{{Name: "old.example.com"}}}
+	newConfig := func(sources ...DomainSourceConfig) *Config {
+		return &Config{NacosUrl: server.URL, Username: "nacos", Password: "secret", DomainSources: sources}
+	}
+
+	manager := NewDomainSourceManager(func() {})
+	defer manager.Close()
+
+	manager.Update(newConfig(nacosSource))
+	if got := len(manager.Merge(&Config{}).Domains); got != 2 {
+		t.Fatalf("合并后的域名数量 = %d, want 2", got)
+	}
+	if got := versionProbes(); got != 1 {
+		t.Fatalf("版本检测次数 = %d, want 1", got)
+	}
+
+	// 监视Nacos客户端的关闭
+	closed := 0
+	fetcher := manager.fetchers["extra"]
+	closeClient := fetcher.close
+	fetcher.close = func() {
+		closed++
+		closeClient()
+	}
+
+	// 其他来源变化时复用未变化来源的Nacos客户端，不重新检测版本
+	manager.Update(newConfig(nacosSource, staticSource))
+	if manager.fetchers["extra"] != fetcher {
+		t.Error("来源配置未变化时应复用获取函数")
+	}
+	if got := versionProbes(); got != 1 {
+		t.Errorf("版本检测次数 = %d, want 1", got)
+	}
+	if closed != 0 {
+		t.Errorf("复用的客户端被关闭了 %d 次", closed)
+	}
+
+	// 来源删除后关闭客户端
+	manager.Update(newConfig(staticSource))
+	if closed != 1 {
+		t.Errorf("删除来源后客户端关闭次数 = %d, want 1", closed)
+	}
+	if _, ok := manager.fetchers["extra"]; ok {
+		t.Error("已删除来源的获取函数仍被保留")
+	}
+	if got := len(manager.Merge(&Config{}).Domains); got != 1 {
+		t.Errorf("合并后的域名数量 = %d, want 1", got)
+	}
+}
+
+func TestDomainSourceManagerCloseReleasesFetchers(t *testing.T) {
+	fake := &fakeNacos{version: "2.3.2", v1Login: true, v2: func() (int, any) {
+		return http.StatusOK, map[string]any{"code": 0, "data": "- a.example.com\n"}
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	manager := NewDomainSourceManager(func() {})
+	manager.Update(&Config{
+		NacosUrl:      server.URL,
+		DomainSources: []DomainSourceConfig{{Name: "extra", Type: "nacos", DataId: "extra-domains"}},
+	})
+
+	closed := false
+	fetcher := manager.fetchers["extra"]
+	closeClient := fetcher.close
+	fetcher.close = func() {
+		closed = true
+		closeClient()
+	}
+
+	manager.Close()
+	if !closed {
+		t.Error("Close() 未关闭来源的客户端")
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// eppStatusCodes EPP状态码（RFC 5731、RFC 3915），键为normalizeEPPStatus的结果
+var eppStatusCodes = func() map[string]string {
+	codes := []string{
+		"ok", "inactive",
+		"clientDeleteProhibited", "clientHold", "clientRenewProhibited", "clientTransferProhibited", "clientUpdateProhibited",
+		"serverDeleteProhibited", "serverHold", "serverRenewProhibited", "serverTransferProhibited", "serverUpdateProhibited",
+		"pendingCreate", "pendingDelete", "pendingRenew", "pendingRestore", "pendingTransfer", "pendingUpdate",
+		"addPeriod", "autoRenewPeriod", "renewPeriod", "transferPeriod", "redemptionPeriod",
+	}
+	normalized := make(map[string]string, len(codes)+1)
+	for _, code := range codes {
+		normalized[strings.ToLower(code)] = code
+	}
+	// RDAP使用active表示EPP的ok（RFC 8056）
+	normalized["active"] = "ok"
+	return normalized
+}()
+
+// normalizeEPPStatus 统一EPP状态码的写法，便于比较：
+// WHOIS返回 "clientTransferProhibited https://icann.org/epp#clientTransferProhibited"，
+// RDAP返回 "client transfer prohibited"，均转换为 "clienttransferprohibited"
+func normalizeEPPStatus(status string) string {
+	var words []string
+	for _, field := range strings.Fields(status) {
+		if strings.Contains(field, "://") || strings.HasPrefix(field, "(") {
+			break
+		}
+		words = append(words, field)
+	}
+	normalized := strings.ToLower(strings.Join(words, ""))
+	return strings.NewReplacer("_", "", "-", "").Replace(normalized)
+}
+
+// statusCodes 将WHOIS/RDAP返回的状态转换为EPP状态码（去重并排序），无法识别的状态保留统一写法后的值
+func statusCodes(statuses []string) []string {
+	seen := make(map[string]struct{}, len(statuses))
+	codes := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		normalized := normalizeEPPStatus(status)
+		if normalized == "" {
+			continue
+		}
+		code, ok := eppStatusCodes[normalized]
+		if !ok {
+			code = normalized
+		}
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// transferLocked 判断是否禁止转移（注册商或注册局设置了TransferProhibited）
+func transferLocked(codes []string) bool {
+	return containsString(codes, "clientTransferProhibited") || containsString(codes, "serverTransferProhibited")
+}
+
+// registryLocked 判断是否开启注册局锁定（注册局同时禁止删除、转移和更新）
+func registryLocked(codes []string) bool {
+	return containsString(codes, "serverDeleteProhibited") &&
+		containsString(codes, "serverTransferProhibited") &&
+		containsString(codes, "serverUpdateProhibited")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeEPPStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{status: "clientTransferProhibited https://icann.org/epp#clientTransferProhibited", want: "clienttransferprohibited"},
+		{status: "serverHold (https://www.icann.org/epp#serverHold)", want: "serverhold"},
+		{status: "client transfer prohibited", want: "clienttransferprohibited"},
+		{status: "pending delete", want: "pendingdelete"},
+		{status: "redemption-period", want: "redemptionperiod"},
+		{status: "AUTO_RENEW_PERIOD", want: "autorenewperiod"},
+		{status: "  ok  ", want: "ok"},
+		{status: "https://icann.org/epp#ok", want: ""},
+		{status: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := normalizeEPPStatus(tt.status); got != tt.want {
+				t.Errorf("normalizeEPPStatus(%q) = %q, want %q", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []string
+		want             []string
+		wantTransferLock bool
+		wantRegistryLock bool
+	}{
+		{
+			name: "WHOIS状态",
+			statuses: []string{
+				"clientDeleteProhibited https://icann.org/epp#clientDeleteProhibited",
+				"clientTransferProhibited https://icann.org/epp#clientTransferProhibited",
+				"clientUpdateProhibited https://icann.org/epp#clientUpdateProhibited",
+			},
+			want:             []string{"clientDeleteProhibited", "clientTransferProhibited", "clientUpdateProhibited"},
+			wantTransferLock: true,
+		},
+		{
+			name:     "RDAP状态",
+			statuses: []string{"active", "pending delete"},
+			want:     []string{"ok", "pendingDelete"},
+		},
+		{
+			name: "WHOIS与RDAP写法去重",
+			statuses: []string{
+				"client transfer prohibited",
+				"clientTransferProhibited https://icann.org/epp#clientTransferProhibited",
+				"ok https://icann.org/epp#ok",
+				"active",
+			},
+			want:             []string{"clientTransferProhibited", "ok"},
+			wantTransferLock: true,
+		},
+		{
+			name: "注册局锁定",
+			statuses: []string{
+				"server delete prohibited",
+				"server transfer prohibited",
+				"server update prohibited",
+			},
+			want:             []string{"serverDeleteProhibited", "serverTransferProhibited", "serverUpdateProhibited"},
+			wantTransferLock: true,
+			wantRegistryLock: true,
+		},
+		{
+			name:     "注册局锁定不完整",
+			statuses: []string{"serverDeleteProhibited", "serverUpdateProhibited"},
+			want:     []string{"serverDeleteProhibited", "serverUpdateProhibited"},
+		},
+		{
+			name:     "保留无法识别的状态",
+			statuses: []string{"Registered", "", "associated"},
+			want:     []string{"associated", "registered"},
+		},
+		{
+			name: "无状态",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes := statusCodes(tt.statuses)
+			if !reflect.DeepEqual(codes, tt.want) {
+				t.Errorf("statusCodes() = %v, want %v", codes, tt.want)
+			}
+			if got := transferLocked(codes); got != tt.wantTransferLock {
+				t.Errorf("transferLocked() = %v, want %v", got, tt.wantTransferLock)
+			}
+			if got := registryLocked(codes); got != tt.wantRegistryLock {
+				t.Errorf("registryLocked() = %v, want %v", got, tt.wantRegistryLock)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// etcdWatchTimeout 单次watch连接的最长保持时间，到期后重新建立以发现失效的连接
+const etcdWatchTimeout = 5 * time.Minute
+
+// EtcdSourceConfig etcd v3配置源连接配置
+type EtcdSourceConfig struct {
+	Endpoints []string `yaml:"endpoints"` // etcd地址列表，如 http://127.0.0.1:2379
+	Username  string   `yaml:"username"`  // 开启认证时的用户名（可选）
+	Password  string   `yaml:"password"`
+	Key       string   `yaml:"key"` // 存放YAML配置的键，默认 /domain-exporter
+}
+
+// EtcdConfigSource 基于etcd v3 HTTP网关（/v3/kv/range、/v3/watch）的配置源
+type EtcdConfigSource struct {
+	configHolder
+	settings    EtcdSourceConfig
+	httpClient  *http.Client
+	watchClient *http.Client // watch专用客户端，由ctx控制超时
+	endpoint    int          // 当前使用的地址下标，请求失败时切换到下一个
+	token       string       // 认证令牌
+	revision    int64        // 最近一次读取到的集群revision
+	contentMD5  string       // 当前配置内容的MD5
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// NewEtcdConfigSource 创建etcd配置源
+func NewEtcdConfigSource(localConfig *Config) (*EtcdConfigSource, error) {
+	settings := localConfig.Etcd
+	if len(settings.Endpoints) == 0 {
+		return nil, fmt.Errorf("未配置etcd地址（etcd.endpoints）")
+	}
+
+	slog.Info("创建etcd配置源", "endpoints", settings.Endpoints, "key", settings.Key)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	httpClient := newSourceHTTPClient(15*time.Second, localConfig.SkipSSLVerify)
+	source := &EtcdConfigSource{
+		configHolder: newConfigHolder(localConfig),
+		settings:     settings,
+		httpClient:   httpClient,
+		watchClient:  &http.Client{Transport: httpClient.Transport},
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+
+	// 初始加载配置
+	if err := source.load(); err != nil {
+		slog.Warn("初始配置加载失败，将使用本地配置", "source", "etcd", "error", err)
+	}
+
+	go source.watch()
+
+	return source, nil
+}
+
+// Name 配置源名称
+func (s *EtcdConfigSource) Name() string {
+	return "etcd"
+}
+
+// watch 监听键的变化，watch失败时回退到定期拉取
+func (s *EtcdConfigSource) watch() {
+	slog.Info("启动etcd配置监听", "fallback_interval", configSourcePollInterval)
+
+	for {
+		err := s.watchOnce()
+		if s.ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			slog.Debug("etcd watch失败，回退到定期拉取", "error", err)
+			s.nextEndpoint()
+			if !sleepContext(s.ctx, configSourcePollInterval) {
+				break
+			}
+		}
+
+		// 重新读取，避免遗漏watch断开期间的变化
+		if err := s.load(); err != nil {
+			slog.Debug("etcd配置读取失败", "error", err)
+		}
+	}
+
+	slog.Info("停止etcd配置监听")
+}
+
+// watchOnce 建立一次watch连接，收到变化事件时重新加载配置，连接超时后正常返回
+func (s *EtcdConfigSource) watchOnce() error {
+	ctx, cancel := context.WithTimeout(s.ctx, etcdWatchTimeout)
+	defer cancel()
+
+	createRequest := map[string]interface{}{
+		"key": base64.StdEncoding.EncodeToString([]byte(s.settings.Key)),
+	}
+	// 从上次读取之后的revision开始监听，避免遗漏两次请求之间的变化
+	if s.revision > 0 {
+		createRequest["start_revision"] = strconv.FormatInt(s.revision+1, 10)
+	}
+	request := map[string]interface{}{"create_request": createRequest}
+	resp, err := s.post(ctx, s.watchClient, "/v3/watch", request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var message struct {
+			Result struct {
+				Canceled     bool              `json:"canceled"`
+				CancelReason string            `json:"cancel_reason"`
+				Events       []json.RawMessage `json:"events"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := decoder.Decode(&message); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("读取watch响应失败: %w", err)
+		}
+		if message.Error != nil {
+			return fmt.Errorf("watch失败: %s", message.Error.Message)
+		}
+		if message.Result.Canceled {
+			return fmt.Errorf("watch被取消: %s", message.Result.CancelReason)
+		}
+
+		if len(message.Result.Events) > 0 {
+			slog.Debug("etcd通知配置已变化，重新加载", "events", len(message.Result.Events))
+			if err := s.load(); err != nil {
+				slog.Debug("etcd配置读取失败", "error", err)
+			}
+		}
+	}
+}
+
+// load 读取键的当前值，内容变化时更新配置
+func (s *EtcdConfigSource) load() error {
+	request := map[string]interface{}{
+		"key": base64.StdEncoding.EncodeToString([]byte(s.settings.Key)),
+	}
+	resp, err := s.post(s.ctx, s.httpClient, "/v3/kv/range", request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// grpc-gateway将int64编码为字符串
+	var rangeResp struct {
+		Header struct {
+			Revision string `json:"revision"`
+		} `json:"header"`
+		Kvs []struct {
+			Value []byte `json:"value"` // base64编码，由encoding/json自动解码
+		} `json:"kvs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rangeResp); err != nil {
+		return fmt.Errorf("解析读取响应失败: %w", err)
+	}
+
+	if revision, err := strconv.ParseInt(rangeResp.Header.Revision, 10, 64); err == nil {
+		s.revision = revision
+	}
+
+	if len(rangeResp.Kvs) == 0 || len(rangeResp.Kvs[0].Value) == 0 {
+		return fmt.Errorf("配置不存在或为空: %s", s.settings.Key)
+	}
+
+	content := string(rangeResp.Kvs[0].Value)
+	if md5 := contentMD5(content); md5 != s.contentMD5 {
+		if err := s.applyContent("etcd", content); err != nil {
+			return err
+		}
+		s.contentMD5 = md5
+	}
+	return nil
+}
+
+// post 向当前地址发送JSON请求，开启认证时携带令牌
+func (s *EtcdConfigSource) post(ctx context.Context, client *http.Client, path string, request interface{}) (*http.Response, error) {
+	if s.settings.Username != "" && s.token == "" {
+		if err := s.authenticate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := s.doPost(ctx, client, path, request)
+	if err != nil {
+		return nil, err
+	}
+
+	// 令牌过期时重新认证一次
+	if resp.StatusCode == http.StatusUnauthorized && s.settings.Username != "" {
+		resp.Body.Close()
+		if err := s.authenticate(ctx); err != nil {
+			return nil, err
+		}
+		if resp, err = s.doPost(ctx, client, path, request); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("请求%s失败: HTTP %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// doPost 发送一次JSON请求
+func (s *EtcdConfigSource) doPost(ctx context.Context, client *http.Client, path string, request interface{}) (*http.Response, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	endpoint := strings.TrimRight(s.settings.Endpoints[s.endpoint], "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", s.token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求%s失败: %w", path, err)
+	}
+	return resp, nil
+}
+
+// authenticate 通过 /v3/auth/authenticate 获取令牌
+func (s *EtcdConfigSource) authenticate(ctx context.Context) error {
+	s.token = ""
+	resp, err := s.doPost(ctx, s.httpClient, "/v3/auth/authenticate", map[string]string{
+		"name":     s.settings.Username,
+		"password": s.settings.Password,
+	})
+	if err != nil {
+		return fmt.Errorf("认证失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var authResp struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil || authResp.Token == "" {
+		return fmt.Errorf("认证失败: HTTP %d", resp.StatusCode)
+	}
+	s.token = authResp.Token
+	slog.Debug("etcd认证令牌已刷新")
+	return nil
+}
+
+// nextEndpoint 切换到下一个地址，并在新地址上重新认证
+func (s *EtcdConfigSource) nextEndpoint() {
+	if len(s.settings.Endpoints) > 1 {
+		s.endpoint = (s.endpoint + 1) % len(s.settings.Endpoints)
+		s.token = ""
+		slog.Debug("切换etcd地址", "endpoint", s.settings.Endpoints[s.endpoint])
+	}
+}
+
+// Close 停止监听
+func (s *EtcdConfigSource) Close() {
+	s.cancel()
+	slog.Info("etcd配置源已关闭")
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"sort"
 	"sync"
 	"time"
 
@@ -12,54 +15,92 @@ import (
 type DomainExporter struct {
 	config           *Config
 	mutex            sync.RWMutex
-	nacosManager     *NacosConfigManager
-	stopChan         chan struct{}
-	triggerChan      chan struct{} // 用于触发立即检查
-	initialCheckDone bool          // 标记是否已完成初始检查
+	source           ConfigSource    // 动态配置来源（配置中心或本地文件），未配置时为nil
+	state            *StateStore     // 最近检查结果，配置state_file时持久化
+	ctx              context.Context // 监控生命周期，Stop时取消以中断进行中的查询
+	cancel           context.CancelFunc
+	triggerChan      chan struct{}        // 用于触发立即检查
+	initialCheckDone bool                 // 标记是否已完成初始检查
+	pendingDomains   map[string]struct{}  // 等待立即检查的域名（由triggerChan通知）
+	pendingAll       bool                 // 是否需要立即检查所有域名
+	onPortChange     func(port int) error // 配置中的端口变化时调用
+
+	// 域名来源：config为baseConfig合并所有域名来源后的结果
+	baseConfig    *Config // 配置源的当前配置（未合并域名来源）
+	domainSources *DomainSourceManager
+	updateMutex   sync.Mutex // 串行化配置更新和域名来源刷新
 
 	// Prometheus指标
 	domainExpiryDays *prometheus.GaugeVec
 	domainExpiryTime *prometheus.GaugeVec
 	domainCheckTime  *prometheus.GaugeVec
 	domainStatus     *prometheus.GaugeVec
+	domainNextCheck  *prometheus.GaugeVec
+
+	// 检查失败指标：失败时保留上次成功获取的过期时间，另行记录数据年龄和失败原因
+	domainDataAge     *prometheus.Desc
+	domainCheckErrors *prometheus.CounterVec
+
+	// 生命周期状态指标（状态集：当前状态为1，其他状态为0）
+	domainLifecycle *prometheus.GaugeVec
+
+	// EPP状态码指标
+	domainStatusCode     *prometheus.GaugeVec
+	domainTransferLocked *prometheus.GaugeVec
+	domainRegistryLocked *prometheus.GaugeVec
+
+	// 注册信息指标（注册商信息通过domain_info输出）
+	domainCreatedTime *prometheus.GaugeVec
+	domainUpdatedTime *prometheus.GaugeVec
+	domainInfo        *prometheus.Desc // 只含内置标签的domain_info描述符
+
+	// TLS证书指标
+	tlsCertExpiryTime *prometheus.GaugeVec
+	tlsCertExpiryDays *prometheus.GaugeVec
+	tlsCertIssuer     *prometheus.GaugeVec
+	tlsChainValid     *prometheus.GaugeVec
+	tlsCheckStatus    *prometheus.GaugeVec
 }
 
 // NewDomainExporter 创建新的exporter
 func NewDomainExporter(localConfig *Config) (*DomainExporter, error) {
-	var finalConfig *Config
-	var nacosManager *NacosConfigManager
+	finalConfig := localConfig
 
-	// 如果启用了Nacos，优先尝试从Nacos获取配置
-	if localConfig.IsNacosEnabled() {
-		var err error
-		nacosManager, err = NewNacosConfigManager(localConfig)
-		if err != nil {
-			slog.Warn("创建Nacos配置管理器失败，使用本地配置", "error", err)
-			finalConfig = localConfig
-		} else {
-			// 尝试从Nacos获取配置
-			if nacosConfig := nacosManager.GetConfig(); nacosConfig != nil {
-				finalConfig = nacosConfig
-				slog.Info("使用Nacos配置", "domain_count", len(nacosConfig.Domains))
-			} else {
-				slog.Info("Nacos配置为空，使用本地配置")
-				finalConfig = localConfig
-			}
-		}
-	} else {
-		slog.Info("Nacos未启用，使用本地配置")
-		finalConfig = localConfig
+	// 如果配置了配置源（配置中心或本地文件），优先使用其中的配置
+	source, err := NewConfigSource(localConfig)
+	if err != nil {
+		slog.Warn("创建配置源失败，使用本地配置", "error", err)
+	} else if source == nil {
+		slog.Info("未配置配置源，使用本地配置")
+	} else if sourceConfig := source.GetConfig(); sourceConfig != nil {
+		finalConfig = sourceConfig
+		slog.Info("使用配置源配置", "source", source.Name(), "domain_count", len(sourceConfig.Domains))
 	}
 
+	// 远程配置的日志级别优先于本地配置
+	setLogLevel(finalConfig.LogLevel)
+
+	// 状态文件路径属于本地配置，不随配置源变化
+	state := NewStateStore(localConfig.StateFile)
+	if err := state.Load(); err != nil {
+		slog.Warn("加载状态文件失败，将重新检查所有域名", "error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
 	exporter := &DomainExporter{
-		config:       finalConfig,
-		nacosManager: nacosManager,
-		stopChan:     make(chan struct{}),
-		triggerChan:  make(chan struct{}, 1), // 缓冲通道，避免阻塞
+		config:         finalConfig,
+		baseConfig:     finalConfig,
+		source:         source,
+		state:          state,
+		ctx:            ctx,
+		cancel:         cancel,
+		triggerChan:    make(chan struct{}, 1), // 缓冲通道，避免阻塞
+		pendingDomains: make(map[string]struct{}),
 		domainExpiryDays: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "domain_expiry_days",
-				Help: "域名距离过期的天数 (-999表示检测失败)",
+				Help: "域名距离过期的天数（检查失败时保留上次成功获取的值）",
 			},
 			[]string{"domain"},
 		),
@@ -84,10 +125,115 @@ func NewDomainExporter(localConfig *Config) (*DomainExporter, error) {
 			},
 			[]string{"domain"},
 		),
+		domainNextCheck: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_next_check_timestamp",
+				Help: "域名下次计划检查时间戳",
+			},
+			[]string{"domain"},
+		),
+		domainInfo: prometheus.NewDesc("domain_info", domainInfoHelp, domainInfoLabels, nil),
+		domainDataAge: prometheus.NewDesc(
+			"domain_expiry_data_age_seconds",
+			"距离上次成功获取域名过期时间的秒数",
+			[]string{"domain"}, nil,
+		),
+		domainCheckErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "domain_check_errors_total",
+				Help: "域名检查失败次数，按失败原因分类（timeout, connect_refused, network, rate_limited, parse_failed, not_found, other）",
+			},
+			[]string{"domain", "reason"},
+		),
+		domainLifecycle: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_lifecycle_state",
+				Help: "域名生命周期状态（当前状态为1，其他为0）：registered, expired, redemption_period, pending_delete, available",
+			},
+			[]string{"domain", "state"},
+		),
+		domainStatusCode: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_status_code",
+				Help: "域名当前的EPP状态码（值恒为1），如clientTransferProhibited、serverDeleteProhibited、clientHold",
+			},
+			[]string{"domain", "code"},
+		),
+		domainTransferLocked: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_transfer_locked",
+				Help: "域名是否禁止转移（存在clientTransferProhibited或serverTransferProhibited，1=是, 0=否）",
+			},
+			[]string{"domain"},
+		),
+		domainRegistryLocked: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_registry_locked",
+				Help: "域名是否开启注册局锁定（同时存在serverDeleteProhibited、serverTransferProhibited、serverUpdateProhibited，1=是, 0=否）",
+			},
+			[]string{"domain"},
+		),
+		domainCreatedTime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_created_timestamp",
+				Help: "域名注册时间戳",
+			},
+			[]string{"domain"},
+		),
+		domainUpdatedTime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_updated_timestamp",
+				Help: "域名注册信息最后更新时间戳",
+			},
+			[]string{"domain"},
+		),
+		tlsCertExpiryTime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_tls_cert_expiry_timestamp",
+				Help: "TLS叶子证书过期时间戳（NotAfter）",
+			},
+			[]string{"domain", "endpoint"},
+		),
+		tlsCertExpiryDays: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_tls_cert_expiry_days",
+				Help: "TLS叶子证书距离过期的天数",
+			},
+			[]string{"domain", "endpoint"},
+		),
+		tlsCertIssuer: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_tls_cert_info",
+				Help: "TLS叶子证书信息（值恒为1）",
+			},
+			[]string{"domain", "endpoint", "issuer", "subject"},
+		),
+		tlsChainValid: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_tls_cert_chain_valid",
+				Help: "TLS证书链校验结果 (1=有效, 0=无效)",
+			},
+			[]string{"domain", "endpoint"},
+		),
+		tlsCheckStatus: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "domain_tls_check_status",
+				Help: "TLS证书检查状态 (1=成功, 0=失败)",
+			},
+			[]string{"domain", "endpoint"},
+		),
 	}
 
+	// 合并其他域名来源（首次同步刷新）
+	exporter.domainSources = NewDomainSourceManager(exporter.refreshDomainSources)
+	exporter.domainSources.Update(finalConfig)
+	exporter.config = exporter.domainSources.Merge(finalConfig)
+
+	// 使用上次保存的结果预先填充指标，避免重启后指标为空
+	exporter.restoreMetricsFromState()
+
 	// 启动配置监听
-	if nacosManager != nil {
+	if source != nil {
 		go exporter.watchConfigUpdates()
 	}
 
@@ -100,6 +246,26 @@ func (e *DomainExporter) Describe(ch chan<- *prometheus.Desc) {
 	e.domainExpiryTime.Describe(ch)
 	e.domainCheckTime.Describe(ch)
 	e.domainStatus.Describe(ch)
+	e.domainNextCheck.Describe(ch)
+	ch <- e.domainDataAge
+	e.domainCheckErrors.Describe(ch)
+	e.domainLifecycle.Describe(ch)
+	e.domainStatusCode.Describe(ch)
+	e.domainTransferLocked.Describe(ch)
+	e.domainRegistryLocked.Describe(ch)
+	e.domainCreatedTime.Describe(ch)
+	e.domainUpdatedTime.Describe(ch)
+	ch <- e.domainInfo
+	e.domainSources.Describe(ch)
+	if e.source != nil {
+		e.source.Describe(ch)
+	}
+	e.tlsCertExpiryTime.Describe(ch)
+	e.tlsCertExpiryDays.Describe(ch)
+	e.tlsCertIssuer.Describe(ch)
+	e.tlsChainValid.Describe(ch)
+	e.tlsCheckStatus.Describe(ch)
+	defaultRateLimiter.Describe(ch)
 }
 
 // Collect 实现Prometheus Collector接口
@@ -111,52 +277,111 @@ func (e *DomainExporter) Collect(ch chan<- prometheus.Metric) {
 	e.domainExpiryTime.Collect(ch)
 	e.domainCheckTime.Collect(ch)
 	e.domainStatus.Collect(ch)
+	e.domainNextCheck.Collect(ch)
+	e.collectDataAge(ch)
+	e.domainCheckErrors.Collect(ch)
+	e.domainLifecycle.Collect(ch)
+	e.domainStatusCode.Collect(ch)
+	e.domainTransferLocked.Collect(ch)
+	e.domainRegistryLocked.Collect(ch)
+	e.domainCreatedTime.Collect(ch)
+	e.domainUpdatedTime.Collect(ch)
+	e.domainSources.Collect(ch)
+	if e.source != nil {
+		e.source.Collect(ch)
+	}
+	e.tlsCertExpiryTime.Collect(ch)
+	e.tlsCertExpiryDays.Collect(ch)
+	e.tlsCertIssuer.Collect(ch)
+	e.tlsChainValid.Collect(ch)
+	e.tlsCheckStatus.Collect(ch)
+	e.collectDomainInfo(ch)
+	defaultRateLimiter.Collect(ch)
+}
+
+// domainInfoHelp domain_info的帮助信息，/probe输出的domain_info使用相同的帮助信息和内置标签
+const domainInfoHelp = "域名元数据信息（值恒为1），携带域名来源、注册商信息和域名配置中的自定义标签"
+
+// domainInfoLabels domain_info的内置标签，自定义标签按名称排序追加在后面
+var domainInfoLabels = []string{"domain", "source", "registrar", "registrar_iana_id", "whois_server", "method"}
+
+// collectDomainInfo 输出domain_info指标，携带域名来源、注册商信息和域名配置中的自定义标签（调用方需持有读锁）。
+// 自定义标签随配置变化，Describe中只声明内置标签的描述符；描述符ID只由指标名决定，
+// 因此带自定义标签的描述符同样能通过注册表的一致性检查
+func (e *DomainExporter) collectDomainInfo(ch chan<- prometheus.Metric) {
+	labelNames := domainLabelNames(e.config.Domains)
+	desc := e.domainInfo
+	if len(labelNames) > 0 {
+		desc = prometheus.NewDesc("domain_info", domainInfoHelp, append(append([]string{}, domainInfoLabels...), labelNames...), nil)
+	}
+
+	seen := make(map[string]struct{}, len(e.config.Domains))
+	for _, entry := range e.config.Domains {
+		if _, ok := seen[entry.Name]; ok {
+			continue
+		}
+		seen[entry.Name] = struct{}{}
+
+		labelValues := append([]string{entry.Name, entry.Source}, e.registrarLabelValues(entry.Name)...)
+		labelValues = append(labelValues, domainLabelValues(entry, labelNames)...)
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, labelValues...)
+	}
+}
+
+// registrarLabelValues 获取domain_info的注册商标签值（registrar, registrar_iana_id, whois_server, method），
+// 使用上次成功获取的域名信息，从未成功或域名未注册时为空字符串
+func (e *DomainExporter) registrarLabelValues(domain string) []string {
+	state, ok := e.state.Get(domain)
+	if !ok || state.Info == nil || state.Lifecycle == lifecycleAvailable {
+		return []string{"", "", "", ""}
+	}
+	info := state.Info
+	return []string{info.Registrar, info.RegistrarIANAID, info.WhoisServer, info.Method}
 }
 
 // StartMonitoring 启动后台监控
 func (e *DomainExporter) StartMonitoring() {
-	// 立即执行一次检查
-	e.checkAllDomains()
+	// 立即检查一次到期的域名（状态文件中在检查间隔内已检查过的域名会被跳过）
+	due, tlsDue := e.dueDomains(time.Now())
+	e.checkDomains(e.ctx, due)
+	e.checkTLSDomains(e.ctx, tlsDue)
+	e.mutex.Lock()
 	e.initialCheckDone = true
+	e.mutex.Unlock()
 
-	// 获取初始检查间隔
-	currentInterval := time.Duration(e.getCurrentConfig().CheckInterval) * time.Second
-	ticker := time.NewTicker(currentInterval)
+	// 调度器定期检查到期的域名，每个域名的检查间隔由上次检查结果决定
+	ticker := time.NewTicker(schedulerTick)
 	defer ticker.Stop()
 
-	slog.Info("启动定时监控", "check_interval_seconds", e.getCurrentConfig().CheckInterval)
+	currentConfig := e.getCurrentConfig()
+	slog.Info("启动定时监控",
+		"check_interval_seconds", currentConfig.CheckInterval,
+		"adaptive_schedule", currentConfig.Schedule.Adaptive)
 
 	for {
 		select {
 		case <-ticker.C:
-			slog.Debug("定时器触发，开始检查域名")
-			e.checkAllDomains()
-
-			// 检查配置是否变化，如果变化则重置定时器
-			newInterval := time.Duration(e.getCurrentConfig().CheckInterval) * time.Second
-			if newInterval != currentInterval {
-				slog.Info("检查间隔已更新",
-					"old_interval_seconds", int(currentInterval.Seconds()),
-					"new_interval_seconds", int(newInterval.Seconds()))
-				currentInterval = newInterval
-				ticker.Reset(currentInterval)
+			due, tlsDue := e.dueDomains(time.Now())
+			if len(due) > 0 {
+				slog.Debug("调度器触发，开始检查到期域名", "domain_count", len(due))
+				e.checkDomains(e.ctx, due)
+			}
+			if len(tlsDue) > 0 {
+				slog.Debug("调度器触发，开始检查到期的TLS证书", "domain_count", len(tlsDue))
+				e.checkTLSDomains(e.ctx, tlsDue)
 			}
 
 		case <-e.triggerChan:
-			slog.Info("收到配置变更触发信号，立即执行域名检查")
-			e.checkAllDomains()
-
-			// 重置定时器，使用最新的检查间隔
-			newInterval := time.Duration(e.getCurrentConfig().CheckInterval) * time.Second
-			if newInterval != currentInterval {
-				slog.Info("配置变更后更新检查间隔",
-					"old_interval_seconds", int(currentInterval.Seconds()),
-					"new_interval_seconds", int(newInterval.Seconds()))
-				currentInterval = newInterval
+			all, domains := e.takePendingChecks()
+			if all {
+				slog.Info("收到触发信号，立即检查所有域名")
+				e.checkAllDomains(e.ctx)
+			} else if len(domains) > 0 {
+				slog.Info("收到触发信号，立即检查受影响的域名", "domains", domains)
+				e.checkDomains(e.ctx, domains)
 			}
-			ticker.Reset(currentInterval)
 
-		case <-e.stopChan:
+		case <-e.ctx.Done():
 			slog.Info("停止定时监控")
 			return
 		}
@@ -165,43 +390,136 @@ func (e *DomainExporter) StartMonitoring() {
 
 // watchConfigUpdates 监听配置更新
 func (e *DomainExporter) watchConfigUpdates() {
-	if e.nacosManager == nil {
+	if e.source == nil {
 		return
 	}
 
-	updateChan := e.nacosManager.GetUpdateChannel()
+	updateChan := e.source.GetUpdateChannel()
 	for {
 		select {
-		case newConfig := <-updateChan:
-			if newConfig != nil {
-				e.mutex.Lock()
-				oldConfig := *e.config // 复制旧配置
-				e.config = newConfig
-				initialCheckDone := e.initialCheckDone
-				e.mutex.Unlock()
-
-				// 详细记录所有配置变化
-				e.logConfigChanges(&oldConfig, newConfig)
-				e.cleanupMetricsForRemovedDomains(&oldConfig, newConfig)
-
-				// 只有在初始检查完成后才触发配置变更检查，避免启动时重复检查
-				if initialCheckDone {
-					select {
-					case e.triggerChan <- struct{}{}:
-						slog.Info("已发送配置变更触发信号")
-					default:
-						slog.Warn("触发通道已满，跳过此次触发信号")
-					}
-				} else {
-					slog.Debug("跳过启动时的配置变更触发，避免重复检查")
-				}
+		case update, ok := <-updateChan:
+			if !ok {
+				return
 			}
-		case <-e.stopChan:
+			if update != nil {
+				e.applyBaseConfig(update.Config)
+			}
+		case <-e.ctx.Done():
 			return
 		}
 	}
 }
 
+// applyBaseConfig 应用配置源的新配置：更新域名来源后与当前合并后的配置比较
+func (e *DomainExporter) applyBaseConfig(config *Config) {
+	e.updateMutex.Lock()
+	defer e.updateMutex.Unlock()
+
+	e.baseConfig = config
+	e.domainSources.Update(config)
+	e.applyConfigUpdate(NewConfigUpdate(e.getCurrentConfig(), e.domainSources.Merge(config)))
+}
+
+// refreshDomainSources 域名来源刷新后重新合并域名列表
+func (e *DomainExporter) refreshDomainSources() {
+	e.updateMutex.Lock()
+	defer e.updateMutex.Unlock()
+
+	update := NewConfigUpdate(e.getCurrentConfig(), e.domainSources.Merge(e.baseConfig))
+	if update.Change.IsEmpty() {
+		return
+	}
+	e.applyConfigUpdate(update)
+}
+
+// applyConfigUpdate 应用配置更新：清理删除的域名，立即检查新增或变化的域名
+func (e *DomainExporter) applyConfigUpdate(update *ConfigUpdate) {
+	change := update.Change
+
+	e.mutex.Lock()
+	e.config = update.Config
+	initialCheckDone := e.initialCheckDone
+	e.mutex.Unlock()
+
+	// 详细记录所有配置变化
+	e.logConfigChanges(change)
+	if change.HasField("log_level") {
+		setLogLevel(update.Config.LogLevel)
+	}
+	e.cleanupMetricsForRemovedDomains(change.RemovedDomains)
+
+	// 端口变化时切换HTTP监听
+	if change.HasField("port") {
+		e.mutex.RLock()
+		onPortChange := e.onPortChange
+		e.mutex.RUnlock()
+		if onPortChange != nil {
+			if err := onPortChange(update.Config.Port); err != nil {
+				slog.Error("切换HTTP监听端口失败，继续使用当前端口", "port", update.Config.Port, "error", err)
+			}
+		}
+	}
+
+	// 调度相关字段变化后刷新下次检查时间指标
+	if change.HasField("check_interval") || change.HasField("schedule") {
+		e.dueDomains(time.Now())
+	}
+
+	// 只有在初始检查完成后才触发配置变更检查，避免启动时重复检查
+	if !initialCheckDone {
+		slog.Debug("跳过启动时的配置变更触发，避免重复检查")
+		return
+	}
+
+	if change.RequiresFullCheck() {
+		e.requestCheck(nil)
+	} else if domains := change.DomainsToCheck(); len(domains) > 0 {
+		e.requestCheck(domains)
+	}
+}
+
+// OnPortChange 设置配置中的端口变化时的回调
+func (e *DomainExporter) OnPortChange(fn func(port int) error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.onPortChange = fn
+}
+
+// requestCheck 请求立即检查指定域名，domains为nil时检查所有域名
+func (e *DomainExporter) requestCheck(domains []string) {
+	e.mutex.Lock()
+	if domains == nil {
+		e.pendingAll = true
+	}
+	for _, domain := range domains {
+		e.pendingDomains[domain] = struct{}{}
+	}
+	e.mutex.Unlock()
+
+	select {
+	case e.triggerChan <- struct{}{}:
+		slog.Info("已发送检查触发信号", "domain_count", len(domains), "all", domains == nil)
+	default:
+		slog.Debug("已有待处理的触发信号，合并到待检查列表")
+	}
+}
+
+// takePendingChecks 取出并清空待检查的域名
+func (e *DomainExporter) takePendingChecks() (bool, []string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	all := e.pendingAll
+	domains := make([]string, 0, len(e.pendingDomains))
+	for domain := range e.pendingDomains {
+		domains = append(domains, domain)
+	}
+	e.pendingAll = false
+	e.pendingDomains = make(map[string]struct{})
+	sort.Strings(domains)
+	return all, domains
+}
+
 // getCurrentConfig 获取当前配置
 func (e *DomainExporter) getCurrentConfig() *Config {
 	e.mutex.RLock()
@@ -211,68 +529,271 @@ func (e *DomainExporter) getCurrentConfig() *Config {
 
 // Stop 停止监控
 func (e *DomainExporter) Stop() {
-	close(e.stopChan)
-	if e.nacosManager != nil {
-		e.nacosManager.Close()
+	e.cancel()
+	e.domainSources.Close()
+	if e.source != nil {
+		e.source.Close()
 	}
 }
 
-// TriggerCheck 手动触发检查（用于外部调用）
+// TriggerCheck 手动触发检查所有域名（用于外部调用）
 func (e *DomainExporter) TriggerCheck() {
-	select {
-	case e.triggerChan <- struct{}{}:
-		slog.Info("手动触发域名检查")
-	default:
-		slog.Info("检查已在进行中，跳过手动触发")
-	}
+	slog.Info("手动触发域名检查")
+	e.requestCheck(nil)
+}
+
+// checkAllDomains 检查所有域名
+func (e *DomainExporter) checkAllDomains(ctx context.Context) {
+	e.checkDomains(ctx, e.getCurrentConfig().DomainNames())
 }
 
-// checkAllDomains 检查所有域名（串行执行）
-func (e *DomainExporter) checkAllDomains() {
+// dueDomains 获取已到下次检查时间的域名，并更新下次检查时间指标。
+// 返回需要检查注册信息的域名，以及只需检查TLS证书的域名（TLS证书与注册信息分开调度）
+func (e *DomainExporter) dueDomains(now time.Time) ([]string, []string) {
 	currentConfig := e.getCurrentConfig()
-	slog.Info("开始串行检查域名", "domain_count", len(currentConfig.Domains))
 
-	// 串行检查每个域名
-	for i, domain := range currentConfig.Domains {
-		slog.Debug("检查进度", "current", i+1, "total", len(currentConfig.Domains), "domain", domain)
-		e.checkDomain(domain)
+	var due, tlsDue []string
+	for _, domain := range currentConfig.DomainNames() {
+		state, ok := e.state.Get(domain)
+		nextCheck := currentConfig.nextCheckTime(state, ok, now)
+		if !nextCheck.After(now) {
+			due = append(due, domain)
+			continue
+		}
+		e.domainNextCheck.WithLabelValues(domain).Set(float64(nextCheck.Unix()))
 
-		// 在域名之间添加短暂延迟，避免对WHOIS服务器造成压力
-		if i < len(currentConfig.Domains)-1 {
-			time.Sleep(time.Second * 1)
+		endpoints, _ := currentConfig.tlsEndpoints(currentConfig.DomainEntry(domain))
+		if len(endpoints) > 0 && !currentConfig.nextTLSCheckTime(state, ok, now).After(now) {
+			tlsDue = append(tlsDue, domain)
 		}
 	}
+	return due, tlsDue
+}
+
+// checkDomains 检查指定域名的注册信息和TLS证书
+func (e *DomainExporter) checkDomains(ctx context.Context, domains []string) {
+	e.runChecks(ctx, domains, e.checkDomain)
+}
 
-	slog.Info("所有域名检查完成")
+// checkTLSDomains 只检查指定域名的TLS证书，不查询WHOIS/RDAP
+func (e *DomainExporter) checkTLSDomains(ctx context.Context, domains []string) {
+	e.runChecks(ctx, domains, func(ctx context.Context, domain string) {
+		currentConfig := e.getCurrentConfig()
+		e.checkTLSCertificates(withLogDomain(ctx, domain), currentConfig.DomainEntry(domain), currentConfig)
+	})
+}
+
+// runChecks 使用有界worker池并发执行检查，完成后保存状态文件
+func (e *DomainExporter) runChecks(ctx context.Context, domains []string, check func(context.Context, string)) {
+	if len(domains) == 0 {
+		return
+	}
+	workers := e.getCurrentConfig().Concurrency
+	if workers > len(domains) {
+		workers = len(domains)
+	}
+	slog.Info("开始并发检查域名", "domain_count", len(domains), "concurrency", workers)
+
+	start := time.Now()
+	domainChan := make(chan string)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 查询频率由按服务器分桶的限速器控制
+			for domain := range domainChan {
+				check(ctx, domain)
+			}
+		}()
+	}
+
+dispatch:
+	for i, domain := range domains {
+		slog.Debug("检查进度", "current", i+1, "total", len(domains), "domain", domain)
+		select {
+		case domainChan <- domain:
+		case <-ctx.Done():
+			slog.Info("监控已停止，取消剩余域名检查", "remaining", len(domains)-i)
+			break dispatch
+		}
+	}
+	close(domainChan)
+	wg.Wait()
+
+	slog.Info("所有域名检查完成", "duration_seconds", int(time.Since(start).Seconds()))
+
+	if err := e.state.Save(); err != nil {
+		slog.Warn("保存状态文件失败", "error", err)
+	}
 }
 
 // checkDomain 检查单个域名
-func (e *DomainExporter) checkDomain(domain string) {
-	slog.Debug("检查域名", "domain", domain)
+func (e *DomainExporter) checkDomain(ctx context.Context, domain string) {
+	// 标记域名，使按域名开启的调试日志生效
+	ctx = withLogDomain(ctx, domain)
+	slog.DebugContext(ctx, "检查域名", "domain", domain)
 
-	// 记录检查时间
 	now := time.Now()
-	e.domainCheckTime.WithLabelValues(domain).Set(float64(now.Unix()))
 
 	// 获取当前配置
 	currentConfig := e.getCurrentConfig()
+	entry := currentConfig.DomainEntry(domain)
 
-	// 获取域名信息（带超时和多种检测方法）
-	timeout := time.Duration(currentConfig.Timeout) * time.Second
-	domainInfo, err := GetDomainInfoWithFallback(domain, timeout, currentConfig)
+	// TLS证书检查与注册信息检查相互独立
+	e.checkTLSCertificates(ctx, entry, currentConfig)
+
+	// 获取域名信息（带超时和多种检测方法），应用域名级的超时和提供者覆盖
+	lookupConfig := currentConfig.forDomain(entry)
+	domainInfo, err := GetDomainInfoWithFallback(ctx, domain, lookupConfig)
 	if err != nil {
-		slog.Error("获取域名信息失败", "domain", domain, "error", err)
-		e.domainStatus.WithLabelValues(domain).Set(0)
-		// 设置失败标记：-999天表示检测失败
-		e.domainExpiryDays.WithLabelValues(domain).Set(-999)
-		// 设置过期时间戳为0表示未知
-		e.domainExpiryTime.WithLabelValues(domain).Set(0)
+		// 停止监控导致的取消不记为检查失败
+		if ctx.Err() != nil {
+			slog.DebugContext(ctx, "域名检查已取消", "domain", domain)
+			return
+		}
+		reason := classifyCheckError(err)
+		slog.ErrorContext(ctx, "获取域名信息失败", "domain", domain, "reason", reason, "error", err)
+		e.recordIfConfigured(ctx, domain, func() {
+			e.domainCheckTime.WithLabelValues(domain).Set(float64(now.Unix()))
+			e.domainCheckErrors.WithLabelValues(domain, reason).Inc()
+			e.state.RecordFailure(domain, now)
+			// 查询返回域名不存在说明域名已释放或从未注册
+			if errors.Is(err, ErrDomainNotFound) {
+				e.logLifecycleChange(ctx, domain, lifecycleAvailable)
+				e.state.RecordLifecycle(domain, lifecycleAvailable)
+			}
+			e.setFailureMetrics(domain)
+			e.updateNextCheckMetric(domain, now)
+		})
+		return
+	}
+
+	var daysUntilExpiryInt float64
+	recorded := e.recordIfConfigured(ctx, domain, func() {
+		e.domainCheckTime.WithLabelValues(domain).Set(float64(now.Unix()))
+		e.logLifecycleChange(ctx, domain, domainInfo.State)
+		e.state.RecordSuccess(domain, domainInfo, now)
+		daysUntilExpiryInt = e.setDomainMetrics(domain, domainInfo)
+		e.updateNextCheckMetric(domain, now)
+	})
+	if !recorded {
+		return
+	}
+
+	slog.InfoContext(ctx, "域名检查完成",
+		"domain", domain,
+		"days_until_expiry", int(daysUntilExpiryInt),
+		"expiry_date", domainInfo.ExpiryDate.Format("2006-01-02"),
+		"state", domainInfo.State,
+		"method", domainInfo.Method)
+}
+
+// recordIfConfigured 在配置更新锁内确认域名仍在当前配置中后写入检查结果，返回是否已写入。
+// 查询期间域名可能已被配置更新删除并清理指标，此时丢弃结果，避免重新写回过期的指标和状态
+func (e *DomainExporter) recordIfConfigured(ctx context.Context, domain string, record func()) bool {
+	e.updateMutex.Lock()
+	defer e.updateMutex.Unlock()
+
+	if !e.getCurrentConfig().HasDomain(domain) {
+		slog.DebugContext(ctx, "域名已从配置中删除，丢弃检查结果", "domain", domain)
+		return false
+	}
+	record()
+	return true
+}
+
+// logLifecycleChange 生命周期状态变化时记录日志，进入赎回期等非正常状态时使用警告级别
+func (e *DomainExporter) logLifecycleChange(ctx context.Context, domain, lifecycle string) {
+	previous, ok := e.state.Get(domain)
+	if !ok || previous.Lifecycle == "" || previous.Lifecycle == lifecycle {
 		return
 	}
+	level := slog.LevelWarn
+	if lifecycle == lifecycleRegistered {
+		level = slog.LevelInfo
+	}
+	slog.Log(ctx, level, "域名生命周期状态变化", "domain", domain, "from", previous.Lifecycle, "to", lifecycle)
+}
 
+// setDomainMetrics 根据域名信息设置成功指标，返回剩余天数
+func (e *DomainExporter) setDomainMetrics(domain string, domainInfo *DomainInfo) float64 {
 	// 设置成功状态
 	e.domainStatus.WithLabelValues(domain).Set(1)
 
+	// 旧版本状态文件中的域名信息没有生命周期状态
+	lifecycle := domainInfo.State
+	if lifecycle == "" {
+		lifecycle = lifecycleState(domainInfo, time.Now())
+	}
+	e.setLifecycleMetric(domain, lifecycle)
+	e.setStatusCodeMetrics(domain, domainInfo)
+	e.setRegistrationMetrics(domain, domainInfo)
+
+	return e.setExpiryMetrics(domain, domainInfo)
+}
+
+// setStatusCodeMetrics 设置EPP状态码及转移锁定、注册局锁定指标，提供者未返回状态码时不输出
+func (e *DomainExporter) setStatusCodeMetrics(domain string, domainInfo *DomainInfo) {
+	// 状态码可能变化，先清理旧的状态码指标
+	e.deleteStatusCodeMetrics(domain)
+
+	codes := statusCodes(domainInfo.Statuses)
+	if len(codes) == 0 {
+		return
+	}
+	for _, code := range codes {
+		e.domainStatusCode.WithLabelValues(domain, code).Set(1)
+	}
+
+	locked := 0.0
+	if transferLocked(codes) {
+		locked = 1
+	}
+	e.domainTransferLocked.WithLabelValues(domain).Set(locked)
+
+	locked = 0
+	if registryLocked(codes) {
+		locked = 1
+	}
+	e.domainRegistryLocked.WithLabelValues(domain).Set(locked)
+}
+
+// setRegistrationMetrics 设置注册时间和最后更新时间指标，提供者未返回时不输出
+func (e *DomainExporter) setRegistrationMetrics(domain string, domainInfo *DomainInfo) {
+	if domainInfo.CreatedDate.IsZero() {
+		e.domainCreatedTime.DeleteLabelValues(domain)
+	} else {
+		e.domainCreatedTime.WithLabelValues(domain).Set(float64(domainInfo.CreatedDate.Unix()))
+	}
+	if domainInfo.UpdatedDate.IsZero() {
+		e.domainUpdatedTime.DeleteLabelValues(domain)
+	} else {
+		e.domainUpdatedTime.WithLabelValues(domain).Set(float64(domainInfo.UpdatedDate.Unix()))
+	}
+}
+
+// deleteStatusCodeMetrics 删除域名的EPP状态码指标
+func (e *DomainExporter) deleteStatusCodeMetrics(domain string) {
+	e.domainStatusCode.DeletePartialMatch(prometheus.Labels{"domain": domain})
+	e.domainTransferLocked.DeleteLabelValues(domain)
+	e.domainRegistryLocked.DeleteLabelValues(domain)
+}
+
+// setLifecycleMetric 设置生命周期状态集指标，当前状态为1，其他状态为0
+func (e *DomainExporter) setLifecycleMetric(domain, lifecycle string) {
+	for _, state := range lifecycleStates {
+		value := 0.0
+		if state == lifecycle {
+			value = 1
+		}
+		e.domainLifecycle.WithLabelValues(domain, state).Set(value)
+	}
+}
+
+// setExpiryMetrics 根据域名信息设置过期时间指标，返回剩余天数
+func (e *DomainExporter) setExpiryMetrics(domain string, domainInfo *DomainInfo) float64 {
 	// 计算剩余天数（取整数）
 	daysUntilExpiry := time.Until(domainInfo.ExpiryDate).Hours() / 24
 	daysUntilExpiryInt := float64(int(daysUntilExpiry))
@@ -281,113 +802,230 @@ func (e *DomainExporter) checkDomain(domain string) {
 	// 设置过期时间戳
 	e.domainExpiryTime.WithLabelValues(domain).Set(float64(domainInfo.ExpiryDate.Unix()))
 
-	slog.Info("域名检查完成",
-		"domain", domain,
-		"days_until_expiry", int(daysUntilExpiryInt),
-		"expiry_date", domainInfo.ExpiryDate.Format("2006-01-02"),
-		"method", domainInfo.Method)
+	return daysUntilExpiryInt
 }
 
-// logConfigChanges 记录配置变化的详细信息
-func (e *DomainExporter) logConfigChanges(oldConfig, newConfig *Config) {
-	changes := make(map[string]interface{})
-
-	// 检查域名列表变化
-	if !equalStringSlices(oldConfig.Domains, newConfig.Domains) {
-		changes["domains"] = map[string]interface{}{
-			"old": oldConfig.Domains,
-			"new": newConfig.Domains,
-		}
-	}
+// checkTLSCertificates 检查域名配置的所有TLS端点
+func (e *DomainExporter) checkTLSCertificates(ctx context.Context, entry DomainEntry, config *Config) {
+	endpoints, serverName := config.tlsEndpoints(entry)
+	domain := entry.Name
+	previous, _ := e.state.Get(domain)
 
-	// 检查检查间隔变化
-	if oldConfig.CheckInterval != newConfig.CheckInterval {
-		changes["check_interval"] = map[string]interface{}{
-			"old": oldConfig.CheckInterval,
-			"new": newConfig.CheckInterval,
+	if len(endpoints) == 0 {
+		if len(previous.TLSCerts) > 0 {
+			e.recordIfConfigured(ctx, domain, func() {
+				e.deleteTLSMetrics(domain, "")
+				e.state.RecordTLS(domain, nil, time.Now())
+			})
 		}
+		return
 	}
 
-	// 检查端口变化
-	if oldConfig.Port != newConfig.Port {
-		changes["port"] = map[string]interface{}{
-			"old": oldConfig.Port,
-			"new": newConfig.Port,
+	timeout := time.Duration(config.forDomain(entry).Timeout) * time.Second
+	certs := make(map[string]*TLSCertInfo, len(endpoints))
+	var checked []*TLSCertInfo
+	var failed []string
+	for _, endpoint := range endpoints {
+		cert, err := CheckTLSCertificate(ctx, endpoint, serverName, timeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.WarnContext(ctx, "TLS证书检查失败", "domain", domain, "endpoint", endpoint, "error", err)
+			failed = append(failed, endpoint)
+			// 保留上一次成功获取的证书信息
+			if cert, ok := previous.TLSCerts[endpoint]; ok {
+				certs[endpoint] = cert
+			}
+			continue
 		}
+
+		certs[endpoint] = cert
+		checked = append(checked, cert)
+		slog.InfoContext(ctx, "TLS证书检查完成",
+			"domain", domain,
+			"endpoint", endpoint,
+			"not_after", cert.NotAfter.Format("2006-01-02"),
+			"issuer", cert.Issuer,
+			"chain_valid", cert.ChainValid)
 	}
 
-	// 检查日志级别变化
-	if oldConfig.LogLevel != newConfig.LogLevel {
-		changes["log_level"] = map[string]interface{}{
-			"old": oldConfig.LogLevel,
-			"new": newConfig.LogLevel,
+	e.recordIfConfigured(ctx, domain, func() {
+		// 清理已不再检查的端点指标
+		for endpoint := range previous.TLSCerts {
+			if !containsString(endpoints, endpoint) {
+				e.deleteTLSMetrics(domain, endpoint)
+			}
+		}
+		for _, endpoint := range failed {
+			e.tlsCheckStatus.WithLabelValues(domain, endpoint).Set(0)
 		}
+		for _, cert := range checked {
+			e.setTLSMetrics(domain, cert)
+		}
+		e.state.RecordTLS(domain, certs, time.Now())
+	})
+}
+
+// setTLSMetrics 根据证书信息设置TLS指标
+func (e *DomainExporter) setTLSMetrics(domain string, cert *TLSCertInfo) {
+	endpoint := cert.Endpoint
+	e.tlsCheckStatus.WithLabelValues(domain, endpoint).Set(1)
+	e.tlsCertExpiryTime.WithLabelValues(domain, endpoint).Set(float64(cert.NotAfter.Unix()))
+	e.tlsCertExpiryDays.WithLabelValues(domain, endpoint).Set(float64(int(time.Until(cert.NotAfter).Hours() / 24)))
+
+	// 证书更换后签发者可能变化，先清理旧的信息指标
+	e.tlsCertIssuer.DeletePartialMatch(prometheus.Labels{"domain": domain, "endpoint": endpoint})
+	e.tlsCertIssuer.WithLabelValues(domain, endpoint, cert.Issuer, cert.Subject).Set(1)
+
+	chainValid := 0.0
+	if cert.ChainValid {
+		chainValid = 1
 	}
+	e.tlsChainValid.WithLabelValues(domain, endpoint).Set(chainValid)
+}
 
-	// 检查超时时间变化
-	if oldConfig.Timeout != newConfig.Timeout {
-		changes["timeout"] = map[string]interface{}{
-			"old": oldConfig.Timeout,
-			"new": newConfig.Timeout,
-		}
+// deleteTLSMetrics 删除TLS端点的指标，endpoint为空时删除域名的所有TLS指标
+func (e *DomainExporter) deleteTLSMetrics(domain, endpoint string) {
+	labels := prometheus.Labels{"domain": domain}
+	if endpoint != "" {
+		labels["endpoint"] = endpoint
 	}
+	e.tlsCertExpiryTime.DeletePartialMatch(labels)
+	e.tlsCertExpiryDays.DeletePartialMatch(labels)
+	e.tlsCertIssuer.DeletePartialMatch(labels)
+	e.tlsChainValid.DeletePartialMatch(labels)
+	e.tlsCheckStatus.DeletePartialMatch(labels)
+}
 
-	// 记录变化
-	if len(changes) > 0 {
-		slog.Info("检测到配置参数变化", "changes", changes)
+// updateNextCheckMetric 根据状态存储更新域名的下次检查时间指标
+func (e *DomainExporter) updateNextCheckMetric(domain string, now time.Time) {
+	state, ok := e.state.Get(domain)
+	nextCheck := e.getCurrentConfig().nextCheckTime(state, ok, now)
+	e.domainNextCheck.WithLabelValues(domain).Set(float64(nextCheck.Unix()))
+}
 
-		// 特别提醒重要变化
-		if _, exists := changes["check_interval"]; exists {
-			slog.Info("检查间隔已更新，将在下次定时器触发时生效")
-		}
-		if _, exists := changes["domains"]; exists {
-			slog.Info("域名列表已更新，立即触发检查")
-		}
+// setFailureMetrics 设置检查失败指标：保留上次成功获取的过期时间，从未成功时不输出过期指标，
+// 避免影响min()/avg()等聚合；开启legacy_failure_sentinel时写入旧版本的-999/0哨兵值
+func (e *DomainExporter) setFailureMetrics(domain string) {
+	e.domainStatus.WithLabelValues(domain).Set(0)
 
-		if _, exists := changes["timeout"]; exists {
-			slog.Info("超时时间已更新，将在下次检查时生效")
-		}
+	// 生命周期状态保持最近一次确定的值（查询返回域名不存在时为available）
+	state, ok := e.state.Get(domain)
+	if ok && state.Lifecycle != "" {
+		e.setLifecycleMetric(domain, state.Lifecycle)
+	}
+
+	// 状态码和注册信息保持上次成功获取的值，域名未注册时不再输出
+	if ok && state.Info != nil && state.Lifecycle != lifecycleAvailable {
+		e.setStatusCodeMetrics(domain, state.Info)
+		e.setRegistrationMetrics(domain, state.Info)
 	} else {
-		slog.Debug("配置已重新加载，但未检测到参数变化")
+		e.deleteStatusCodeMetrics(domain)
+		e.domainCreatedTime.DeleteLabelValues(domain)
+		e.domainUpdatedTime.DeleteLabelValues(domain)
+	}
+
+	if e.getCurrentConfig().LegacyFailureSentinel {
+		// 设置失败标记：-999天表示检测失败，过期时间戳为0表示未知
+		e.domainExpiryDays.WithLabelValues(domain).Set(-999)
+		e.domainExpiryTime.WithLabelValues(domain).Set(0)
+		return
+	}
+
+	if ok && state.Info != nil {
+		e.setExpiryMetrics(domain, state.Info)
+		return
 	}
+	e.domainExpiryDays.DeleteLabelValues(domain)
+	e.domainExpiryTime.DeleteLabelValues(domain)
 }
 
-// equalStringSlices 比较两个字符串切片是否相等
-func equalStringSlices(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
+// collectDataAge 输出各域名距离上次成功获取过期时间的秒数（调用方需持有读锁）
+func (e *DomainExporter) collectDataAge(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	for _, domain := range e.config.DomainNames() {
+		state, ok := e.state.Get(domain)
+		if !ok || state.LastSuccess.IsZero() {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(e.domainDataAge, prometheus.GaugeValue, now.Sub(state.LastSuccess).Seconds(), domain)
 	}
-	for i, v := range a {
-		if v != b[i] {
-			return false
+}
+
+// restoreMetricsFromState 使用状态存储中的上次检查结果填充指标
+func (e *DomainExporter) restoreMetricsFromState() {
+	restored := 0
+	for _, domain := range e.getCurrentConfig().DomainNames() {
+		state, ok := e.state.Get(domain)
+		if !ok {
+			continue
 		}
+
+		e.domainCheckTime.WithLabelValues(domain).Set(float64(state.LastCheck.Unix()))
+		if state.Success && state.Info != nil {
+			e.setDomainMetrics(domain, state.Info)
+		} else {
+			e.setFailureMetrics(domain)
+		}
+		for _, cert := range state.TLSCerts {
+			e.setTLSMetrics(domain, cert)
+		}
+		e.updateNextCheckMetric(domain, time.Now())
+		restored++
+	}
+
+	if restored > 0 {
+		slog.Info("已从状态文件恢复域名指标", "domain_count", restored)
 	}
-	return true
 }
 
-// cleanupMetricsForRemovedDomains 移除已经从配置中删除的域名指标数据
-func (e *DomainExporter) cleanupMetricsForRemovedDomains(oldConfig, newConfig *Config) {
-	if oldConfig == nil {
+// logConfigChanges 记录配置变化的详细信息
+func (e *DomainExporter) logConfigChanges(change ConfigChange) {
+	if change.IsEmpty() {
+		slog.Debug("配置已重新加载，但未检测到参数变化")
 		return
 	}
 
-	removed := make(map[string]struct{})
-	for _, domain := range oldConfig.Domains {
-		removed[domain] = struct{}{}
+	slog.Info("检测到配置参数变化",
+		"added_domains", change.AddedDomains,
+		"removed_domains", change.RemovedDomains,
+		"changed_domains", change.ChangedDomains,
+		"fields", change.Fields)
+
+	// 特别提醒重要变化
+	if change.HasField("check_interval") || change.HasField("schedule") {
+		slog.Info("检查间隔已更新，将在下次调度时生效")
 	}
-	for _, domain := range newConfig.Domains {
-		delete(removed, domain)
+	if len(change.DomainsToCheck()) > 0 {
+		slog.Info("域名列表已更新，立即检查新增或查询配置变化的域名")
 	}
-
-	if len(removed) == 0 {
-		return
+	if len(change.ChangedDomains) > len(change.LookupChanged) {
+		slog.Info("域名标签已更新，将在下次采集时生效，不重新查询")
+	}
+	if change.RequiresFullCheck() {
+		slog.Info("查询相关配置已更新，立即重新检查所有域名")
 	}
+	if change.HasField("timeout") {
+		slog.Info("超时时间已更新，将在下次检查时生效")
+	}
+}
 
-	for domain := range removed {
+// cleanupMetricsForRemovedDomains 移除已经从配置中删除的域名指标数据
+func (e *DomainExporter) cleanupMetricsForRemovedDomains(removed []string) {
+	for _, domain := range removed {
 		e.domainExpiryDays.DeleteLabelValues(domain)
 		e.domainExpiryTime.DeleteLabelValues(domain)
 		e.domainCheckTime.DeleteLabelValues(domain)
 		e.domainStatus.DeleteLabelValues(domain)
+		e.domainNextCheck.DeleteLabelValues(domain)
+		e.domainCheckErrors.DeletePartialMatch(prometheus.Labels{"domain": domain})
+		e.domainLifecycle.DeletePartialMatch(prometheus.Labels{"domain": domain})
+		e.deleteStatusCodeMetrics(domain)
+		e.domainCreatedTime.DeleteLabelValues(domain)
+		e.domainUpdatedTime.DeleteLabelValues(domain)
+		e.deleteTLSMetrics(domain, "")
+		e.state.Delete(domain)
 		slog.Info("清理已删除域名的指标", "domain", domain)
 	}
 }
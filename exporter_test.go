@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// blockingProvider 查询开始后阻塞，直到测试放行
+type blockingProvider struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProvider) Name() string { return "test-blocking" }
+
+func (p *blockingProvider) Lookup(ctx context.Context, domain string) (*DomainInfo, error) {
+	close(p.started)
+	<-p.release
+	return &DomainInfo{Domain: domain, ExpiryDate: time.Now().AddDate(1, 0, 0), Method: "test"}, nil
+}
+
+func TestCheckDomainDiscardsResultForRemovedDomain(t *testing.T) {
+	provider := &blockingProvider{started: make(chan struct{}), release: make(chan struct{})}
+	RegisterProvider(provider.Name(), func(*Config) DomainInfoProvider { return provider })
+
+	newConfig := func(domains ...string) *Config {
+		config := &Config{Providers: ProviderConfig{Default: []string{provider.Name()}}}
+		for _, domain := range domains {
+			config.Domains = append(config.Domains, DomainEntry{Name: domain})
+		}
+		applyDefaults(config)
+		return config
+	}
+
+	exporter, err := NewDomainExporter(newConfig("a.com", "b.com"))
+	if err != nil {
+		t.Fatalf("NewDomainExporter() error = %v", err)
+	}
+	defer exporter.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		exporter.checkDomain(context.Background(), "b.com")
+	}()
+
+	// 查询进行中时从配置中删除b.com
+	<-provider.started
+	exporter.applyBaseConfig(newConfig("a.com"))
+	close(provider.release)
+	<-done
+
+	if _, ok := exporter.state.Get("b.com"); ok {
+		t.Error("已删除域名的检查结果被写入状态存储")
+	}
+	if exporter.domainStatus.DeleteLabelValues("b.com") {
+		t.Error("已删除域名的检查结果被写回domain_check_status指标")
+	}
+	if exporter.domainExpiryTime.DeleteLabelValues("b.com") {
+		t.Error("已删除域名的检查结果被写回domain_expiry_timestamp指标")
+	}
+}
+
+func TestDomainInfoPedanticRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		domains []DomainEntry
+	}{
+		{name: "只有内置标签", domains: []DomainEntry{{Name: "a.com"}, {Name: "b.com"}}},
+		{name: "自定义标签", domains: []DomainEntry{{Name: "a.com", Labels: map[string]string{"team": "ops", "source": "x"}}, {Name: "b.com"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Domains: tt.domains}
+			applyDefaults(config)
+			exporter, err := NewDomainExporter(config)
+			if err != nil {
+				t.Fatalf("NewDomainExporter() error = %v", err)
+			}
+			defer exporter.Stop()
+
+			registry := prometheus.NewPedanticRegistry()
+			if err := registry.Register(exporter); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			families, err := registry.Gather()
+			if err != nil {
+				t.Fatalf("Gather() error = %v", err)
+			}
+
+			for _, family := range families {
+				if family.GetName() == "domain_info" {
+					if got := len(family.GetMetric()); got != len(tt.domains) {
+						t.Errorf("domain_info数量 = %d, want %d", got, len(tt.domains))
+					}
+					return
+				}
+			}
+			t.Error("未输出domain_info")
+		})
+	}
+}
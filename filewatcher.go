@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// filePollInterval 检查本地配置文件变化的间隔
+const filePollInterval = 10 * time.Second
+
+// FileConfigWatcher 本地配置文件监听器，文件内容变化或收到SIGHUP时重新加载配置
+type FileConfigWatcher struct {
+	configHolder
+	filename    string
+	contentHash string // 当前配置文件内容的SHA256，用于判断是否变化
+	reloadMutex sync.Mutex
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// NewFileConfigWatcher 创建本地配置文件监听器，config为启动时已加载的配置
+func NewFileConfigWatcher(config *Config) *FileConfigWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher := &FileConfigWatcher{
+		configHolder: newConfigHolder(config),
+		filename:     config.ConfigFile,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+
+	// 记录当前文件内容，避免启动后第一次轮询被误判为变化
+	if data, err := os.ReadFile(watcher.filename); err == nil {
+		watcher.contentHash = contentHash(data)
+	}
+
+	go watcher.start()
+
+	slog.Info("启动本地配置文件监听", "file", watcher.filename, "poll_interval", filePollInterval)
+	return watcher
+}
+
+// start 定期检查文件内容，并在收到SIGHUP时立即重新加载
+func (w *FileConfigWatcher) start() {
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+
+	ticker := time.NewTicker(filePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.reload(false); err != nil {
+				slog.Warn("重新加载本地配置文件失败，继续使用当前配置", "file", w.filename, "error", err)
+			}
+		case <-hupChan:
+			slog.Info("收到SIGHUP信号，重新加载本地配置文件", "file", w.filename)
+			if err := w.reload(true); err != nil {
+				slog.Error("重新加载本地配置文件失败，继续使用当前配置", "file", w.filename, "error", err)
+			}
+		case <-w.ctx.Done():
+			slog.Info("停止本地配置文件监听")
+			return
+		}
+	}
+}
+
+// reload 读取配置文件，内容变化（或force为true）时解析并发送配置更新
+func (w *FileConfigWatcher) reload(force bool) error {
+	w.reloadMutex.Lock()
+	defer w.reloadMutex.Unlock()
+
+	// Kubernetes ConfigMap通过替换符号链接更新，每次都重新读取文件内容
+	data, err := os.ReadFile(w.filename)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	hash := contentHash(data)
+	if hash == w.contentHash && !force {
+		return nil
+	}
+
+	// 解析或校验失败（如文件正在写入）时保留当前配置，内容再次变化时重新加载
+	w.contentHash = hash
+	fileConfig, err := ParseConfig(data)
+	if err != nil {
+		return w.reject("file", string(data), "invalid", fmt.Errorf("配置文件无效: %w", err))
+	}
+
+	newConfig := buildConfig(fileConfig)
+	newConfig.ConfigFile = w.filename
+
+	return w.apply("file", string(data), newConfig)
+}
+
+// Name 配置源名称
+func (w *FileConfigWatcher) Name() string {
+	return "file"
+}
+
+// Close 停止监听
+func (w *FileConfigWatcher) Close() {
+	w.cancel()
+}
+
+// contentHash 计算配置文件内容的SHA256
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
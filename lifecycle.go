@@ -0,0 +1,37 @@
+package main
+
+import "time"
+
+// 域名生命周期状态，作为domain_lifecycle_state的state标签
+const (
+	lifecycleRegistered       = "registered"        // 正常注册
+	lifecycleExpired          = "expired"           // 已过期，处于自动续费宽限期（autoRenewPeriod）
+	lifecycleRedemptionPeriod = "redemption_period" // 赎回期，需支付赎回费才能恢复
+	lifecyclePendingDelete    = "pending_delete"    // 等待删除，已无法赎回
+	lifecycleAvailable        = "available"         // 未注册（查询返回域名不存在）
+)
+
+// lifecycleStates 所有生命周期状态，用于输出状态集指标
+var lifecycleStates = []string{
+	lifecycleRegistered,
+	lifecycleExpired,
+	lifecycleRedemptionPeriod,
+	lifecyclePendingDelete,
+	lifecycleAvailable,
+}
+
+// lifecycleState 根据EPP状态码和过期时间推断域名生命周期状态
+func lifecycleState(info *DomainInfo, now time.Time) string {
+	codes := statusCodes(info.Statuses)
+
+	switch {
+	case containsString(codes, "pendingDelete"):
+		return lifecyclePendingDelete
+	case containsString(codes, "redemptionPeriod"), containsString(codes, "pendingRestore"):
+		return lifecycleRedemptionPeriod
+	case containsString(codes, "autoRenewPeriod"), !info.ExpiryDate.IsZero() && info.ExpiryDate.Before(now):
+		return lifecycleExpired
+	default:
+		return lifecycleRegistered
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLifecycleState(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	future := now.AddDate(1, 0, 0)
+	past := now.AddDate(0, 0, -10)
+
+	tests := []struct {
+		name string
+		info DomainInfo
+		want string
+	}{
+		{
+			name: "正常注册",
+			info: DomainInfo{ExpiryDate: future, Statuses: []string{"clientTransferProhibited https://icann.org/epp#clientTransferProhibited"}},
+			want: lifecycleRegistered,
+		},
+		{
+			name: "无过期时间和状态",
+			info: DomainInfo{},
+			want: lifecycleRegistered,
+		},
+		{
+			name: "过期时间已过",
+			info: DomainInfo{ExpiryDate: past, Statuses: []string{"ok https://icann.org/epp#ok"}},
+			want: lifecycleExpired,
+		},
+		{
+			name: "自动续费宽限期（注册局已自动续期）",
+			info: DomainInfo{ExpiryDate: future, Statuses: []string{"autoRenewPeriod https://icann.org/epp#autoRenewPeriod"}},
+			want: lifecycleExpired,
+		},
+		{
+			name: "RDAP赎回期",
+			info: DomainInfo{ExpiryDate: past, Statuses: []string{"redemption period", "client hold"}},
+			want: lifecycleRedemptionPeriod,
+		},
+		{
+			name: "等待恢复视为赎回期",
+			info: DomainInfo{ExpiryDate: past, Statuses: []string{"pendingRestore https://icann.org/epp#pendingRestore"}},
+			want: lifecycleRedemptionPeriod,
+		},
+		{
+			name: "RDAP等待删除",
+			info: DomainInfo{ExpiryDate: past, Statuses: []string{"active", "pending delete"}},
+			want: lifecyclePendingDelete,
+		},
+		{
+			name: "等待删除优先于赎回期",
+			info: DomainInfo{ExpiryDate: past, Statuses: []string{"redemptionPeriod", "pendingDelete"}},
+			want: lifecyclePendingDelete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lifecycleState(&tt.info, now); got != tt.want {
+				t.Errorf("lifecycleState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logLevel 全局日志级别，配置更新时动态调整
+var logLevel = new(slog.LevelVar)
+
+// parseLogLevel 解析日志级别字符串，无法识别时使用info
+func parseLogLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// setLogLevel 更新全局日志级别
+func setLogLevel(level string) {
+	newLevel := parseLogLevel(level)
+	if oldLevel := logLevel.Level(); oldLevel != newLevel {
+		logLevel.Set(newLevel)
+		slog.Info("日志级别已更新", "old", oldLevel, "new", newLevel)
+	}
+}
+
+// maxDomainDebugDuration 单个域名调试日志的最长开启时间
+const maxDomainDebugDuration = time.Hour
+
+// domainDebugRegistry 临时开启调试日志的域名及其截止时间
+type domainDebugRegistry struct {
+	mutex   sync.RWMutex
+	domains map[string]time.Time
+}
+
+// domainDebug 全局域名调试日志开关
+var domainDebug = &domainDebugRegistry{domains: make(map[string]time.Time)}
+
+// Enable 在指定时间内为域名开启调试日志，返回截止时间
+func (r *domainDebugRegistry) Enable(domain string, duration time.Duration) time.Time {
+	if duration > maxDomainDebugDuration {
+		duration = maxDomainDebugDuration
+	}
+	until := time.Now().Add(duration)
+
+	r.mutex.Lock()
+	r.domains[strings.ToLower(domain)] = until
+	r.mutex.Unlock()
+	return until
+}
+
+// Disable 关闭域名的调试日志
+func (r *domainDebugRegistry) Disable(domain string) {
+	r.mutex.Lock()
+	delete(r.domains, strings.ToLower(domain))
+	r.mutex.Unlock()
+}
+
+// Active 域名当前是否开启了调试日志
+func (r *domainDebugRegistry) Active(domain string) bool {
+	r.mutex.RLock()
+	until, ok := r.domains[strings.ToLower(domain)]
+	r.mutex.RUnlock()
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		r.Disable(domain)
+		return false
+	}
+	return true
+}
+
+// List 返回当前开启调试日志的域名及截止时间（清理已过期的项）
+func (r *domainDebugRegistry) List() map[string]time.Time {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	result := make(map[string]time.Time, len(r.domains))
+	for domain, until := range r.domains {
+		if now.After(until) {
+			delete(r.domains, domain)
+			continue
+		}
+		result[domain] = until
+	}
+	return result
+}
+
+// logDomainKey 上下文中正在检查的域名
+type logDomainKey struct{}
+
+// withLogDomain 在上下文中标记正在检查的域名，使该域名的调试日志开关生效
+func withLogDomain(ctx context.Context, domain string) context.Context {
+	return context.WithValue(ctx, logDomainKey{}, domain)
+}
+
+// domainDebugHandler 在全局日志级别之外，为开启调试的域名输出所有级别的日志
+type domainDebugHandler struct {
+	handler slog.Handler
+}
+
+// newDomainDebugHandler 包装日志处理器，内部处理器应接受所有级别
+func newDomainDebugHandler(handler slog.Handler) *domainDebugHandler {
+	return &domainDebugHandler{handler: handler}
+}
+
+func (h *domainDebugHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= logLevel.Level() {
+		return true
+	}
+	if ctx == nil {
+		return false
+	}
+	domain, ok := ctx.Value(logDomainKey{}).(string)
+	return ok && domainDebug.Active(domain)
+}
+
+func (h *domainDebugHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *domainDebugHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &domainDebugHandler{handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *domainDebugHandler) WithGroup(name string) slog.Handler {
+	return &domainDebugHandler{handler: h.handler.WithGroup(name)}
+}
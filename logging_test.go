@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDomainDebugCaseInsensitive(t *testing.T) {
+	registry := &domainDebugRegistry{domains: make(map[string]time.Time)}
+
+	// 配置中的域名可能包含大写字母，日志中的domain属性使用配置中的写法
+	registry.Enable("example.com", time.Minute)
+	if !registry.Active("Example.COM") {
+		t.Error("Active() 应不区分大小写")
+	}
+	registry.Disable("EXAMPLE.com")
+	if registry.Active("example.com") {
+		t.Error("Disable() 应不区分大小写")
+	}
+
+	config := &Config{Domains: []DomainEntry{{Name: "Example.com"}, {Name: "example.org"}}}
+	if name, ok := config.LookupDomainName("example.com"); !ok || name != "Example.com" {
+		t.Errorf("LookupDomainName() = %q, %v, want %q, true", name, ok, "Example.com")
+	}
+	if _, ok := config.LookupDomainName("example.net"); ok {
+		t.Error("LookupDomainName() 未配置的域名应返回false")
+	}
+}
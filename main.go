@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -8,8 +9,10 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -21,6 +24,11 @@ var (
 )
 
 func main() {
+	// validate子命令：校验配置文件后退出，可在CI中推送配置到Nacos前使用
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
+
 	flag.Parse()
 
 	// 加载配置
@@ -29,36 +37,13 @@ func main() {
 		log.Fatalf("加载配置文件失败: %v", err)
 	}
 
-	// 根据配置设置日志级别
-	logLevel := slog.LevelInfo
-	switch strings.ToLower(config.LogLevel) {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "warn", "warning":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-	}
-	
-	// 环境变量可以覆盖配置文件
-	if envLogLevel := os.Getenv("LOG_LEVEL"); envLogLevel != "" {
-		switch strings.ToLower(envLogLevel) {
-		case "debug":
-			logLevel = slog.LevelDebug
-		case "warn", "warning":
-			logLevel = slog.LevelWarn
-		case "error":
-			logLevel = slog.LevelError
-		default:
-			logLevel = slog.LevelInfo
-		}
-	}
-	
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
+	// 根据配置设置日志级别（环境变量已在加载配置时覆盖配置文件），运行时可随配置更新动态调整
+	logLevel.Set(parseLogLevel(config.LogLevel))
+
+	// 内部处理器接受所有级别，由包装处理器按全局级别和域名调试开关过滤
+	logger := slog.New(newDomainDebugHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	})))
 	slog.SetDefault(logger)
 
 	// 打印详细的配置信息用于调试
@@ -67,6 +52,8 @@ func main() {
 		"check_interval", config.CheckInterval,
 		"port", config.Port,
 		"timeout", config.Timeout,
+		"concurrency", config.Concurrency,
+		"config_source", config.ConfigSourceType(),
 		"nacos_enabled", config.IsNacosEnabled())
 	
 	// 如果启用了Nacos，打印详细的Nacos配置
@@ -102,6 +89,7 @@ func main() {
 
 	// 设置HTTP路由
 	http.Handle("/metrics", promhttp.Handler())
+	http.Handle("/probe", NewProbeHandler(exporter))
 	http.HandleFunc("/trigger", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -111,20 +99,62 @@ func main() {
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		fmt.Fprintf(w, `{"status": "triggered", "message": "域名检查已触发"}`)
 	})
+	http.HandleFunc("/debug/domain", func(w http.ResponseWriter, r *http.Request) {
+		domain := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("domain")))
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			json.NewEncoder(w).Encode(domainDebug.List())
+		case http.MethodPost:
+			if domain == "" {
+				http.Error(w, "缺少domain参数", http.StatusBadRequest)
+				return
+			}
+			duration := 10 * time.Minute
+			if val := r.URL.Query().Get("duration"); val != "" {
+				parsed, err := time.ParseDuration(val)
+				if err != nil || parsed <= 0 {
+					http.Error(w, "无效的duration参数", http.StatusBadRequest)
+					return
+				}
+				duration = parsed
+			}
+			until := domainDebug.Enable(domain, duration)
+			slog.Info("已开启域名调试日志", "domain", domain, "until", until)
+
+			// 立即检查一次，便于查看完整的查询和解析过程（仅限配置中的域名，避免产生无法清理的指标）
+			// 配置中的域名可能包含大写字母，按配置中的写法触发检查
+			if name, ok := exporter.getCurrentConfig().LookupDomainName(domain); ok {
+				exporter.requestCheck([]string{name})
+			} else {
+				slog.Info("域名不在配置中，仅开启调试日志", "domain", domain)
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			json.NewEncoder(w).Encode(map[string]string{"status": "enabled", "domain": domain, "until": until.Format(time.RFC3339)})
+		case http.MethodDelete:
+			if domain == "" {
+				http.Error(w, "缺少domain参数", http.StatusBadRequest)
+				return
+			}
+			domainDebug.Disable(domain)
+			slog.Info("已关闭域名调试日志", "domain", domain)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			json.NewEncoder(w).Encode(map[string]string{"status": "disabled", "domain": domain})
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
 	http.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
 		currentConfig := exporter.getCurrentConfig()
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		
 		// 构建详细的配置信息
-		domainsJson := "["
-		for i, domain := range currentConfig.Domains {
-			if i > 0 {
-				domainsJson += ","
-			}
-			domainsJson += fmt.Sprintf(`"%s"`, domain)
+		domainsJson, err := json.Marshal(currentConfig.Domains)
+		if err != nil {
+			domainsJson = []byte("[]")
 		}
-		domainsJson += "]"
-		
+
 		fmt.Fprintf(w, `{
 			"domains": %s,
 			"domain_count": %d,
@@ -132,16 +162,19 @@ func main() {
 			"port": %d,
 			"log_level": "%s",
 			"timeout": %d,
-			"detection_method": "whois",
-			"execution_mode": "serial",
+			"detection_method": "%s",
+			"execution_mode": "concurrent",
+			"concurrency": %d,
+			"config_source": "%s",
 			"nacos_enabled": %t,
 			"nacos_url": "%s",
 			"nacos_namespace": "%s",
 			"nacos_data_id": "%s",
 			"nacos_group": "%s"
-		}`, domainsJson, len(currentConfig.Domains), currentConfig.CheckInterval, currentConfig.Port,
+		}`, string(domainsJson), len(currentConfig.Domains), currentConfig.CheckInterval, currentConfig.Port,
 			currentConfig.LogLevel, currentConfig.Timeout,
-			currentConfig.IsNacosEnabled(),
+			strings.Join(currentConfig.ProviderChain(""), ","), currentConfig.Concurrency,
+			currentConfig.ConfigSourceType(), currentConfig.IsNacosEnabled(),
 			currentConfig.NacosUrl, currentConfig.NamespaceId, currentConfig.DataId, currentConfig.Group)
 	})
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -183,6 +216,8 @@ func main() {
 			<li><strong>Metrics</strong>: Prometheus 格式的监控指标</li>
 			<li><strong>手动触发检查</strong>: 立即执行一次域名过期检查</li>
 			<li><strong>查看配置</strong>: 显示当前的配置信息</li>
+			<li><strong>域名调试日志</strong>: <code>POST /debug/domain?domain=example.com&amp;duration=10m</code> 临时为单个域名输出debug日志，已配置的域名会立即检查一次</li>
+			<li><strong>按需检查</strong>: <code>/probe?target=example.com</code> 同步检查单个域名（blackbox-exporter风格，结果会被缓存）</li>
 		</ul>
 	</div>
 	<script>
@@ -201,34 +236,40 @@ func main() {
 </html>`)
 	})
 
-	// 启动HTTP服务
-	serverPort := *port
-	if serverPort == "" {
-		if config.Port != 0 {
-			serverPort = fmt.Sprintf("%d", config.Port)
-		} else {
-			serverPort = "8080" // 默认端口
+	// 启动HTTP服务：命令行或环境变量指定的端口固定不变，否则随配置中的port切换
+	pinned := *port != "" || os.Getenv("PORT") != ""
+	serverPort := exporter.getCurrentConfig().Port
+	if *port != "" {
+		serverPort, err = strconv.Atoi(*port)
+		if err != nil {
+			slog.Error("无效的端口参数", "port", *port, "error", err)
+			os.Exit(1)
 		}
+	} else if os.Getenv("PORT") != "" {
+		serverPort = config.Port
 	}
-
-	slog.Info("启动HTTP服务", "port", serverPort)
-	server := &http.Server{
-		Addr:    ":" + serverPort,
-		Handler: nil,
+	if serverPort == 0 {
+		serverPort = 8080 // 默认端口
 	}
 
-	// 优雅关闭
-	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		<-sigChan
-		slog.Info("收到关闭信号，正在关闭服务...")
-		exporter.Stop()
-		server.Close()
-	}()
-
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	server := NewHTTPServer(http.DefaultServeMux, pinned)
+	prometheus.MustRegister(server)
+	if err := server.Start(serverPort); err != nil {
 		slog.Error("HTTP服务启动失败", "error", err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+
+	// 同步当前配置中的端口（端口固定时仅更新不一致指标），并在配置更新时切换监听
+	if err := server.Rebind(exporter.getCurrentConfig().Port); err != nil {
+		slog.Error("切换HTTP监听端口失败，继续使用当前端口", "error", err)
+	}
+	exporter.OnPortChange(server.Rebind)
+
+	// 优雅关闭
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+	slog.Info("收到关闭信号，正在关闭服务...")
+	exporter.Stop()
+	server.Close()
+}
@@ -1,28 +1,40 @@
 package main
 
 import (
+	"context"
+	"crypto/md5"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
-
-	"gopkg.in/yaml.v2"
 )
 
+// nacosPollInterval 长轮询失败时回退到定期拉取的间隔
+const nacosPollInterval = 10 * time.Second
+
+// nacosLongPollTimeout 长轮询挂起时间，服务端在配置变化或超时后返回
+const nacosLongPollTimeout = 30 * time.Second
+
 // NacosConfigManager Nacos HTTP API 配置管理器
 type NacosConfigManager struct {
+	configHolder
 	httpClient   *http.Client
-	config       *Config
-	configMutex  sync.RWMutex
-	updateChan   chan *Config
+	listenClient *http.Client // 长轮询专用客户端，超时时间需大于挂起时间
 	accessToken  string
 	tokenExpiry  time.Time
+	contentMD5   string // 当前配置内容的MD5，用于长轮询比对
+	apiVersion   string // 实际使用的配置API版本: v1, v2
 	stopChan     chan struct{}
+	ctx          context.Context // Close时取消，中断挂起中的长轮询
+	cancel       context.CancelFunc
 }
 
 // NewNacosConfigManager 创建基于 HTTP API 的 Nacos 配置管理器
@@ -38,8 +50,24 @@ func NewNacosConfigManager(localConfig *Config) (*NacosConfigManager, error) {
 		"username", localConfig.Username,
 		"data_id", localConfig.DataId,
 		"group", localConfig.Group,
-		"poll_interval", "10s")
+		"api_version", localConfig.NacosAPIVersion,
+		"long_poll_timeout", nacosLongPollTimeout)
+
+	manager := newNacosClient(localConfig)
+
+	// 初始加载配置
+	if err := manager.loadConfig(); err != nil {
+		slog.Warn("初始配置加载失败，将使用本地配置", "error", err)
+	}
 
+	// 启动配置监听（长轮询，失败时回退到定期拉取）
+	go manager.startListening()
+
+	return manager, nil
+}
+
+// newNacosClient 创建Nacos HTTP客户端（不加载配置、不启动监听），也用于读取其他dataId
+func newNacosClient(localConfig *Config) *NacosConfigManager {
 	// 创建 HTTP 客户端
 	httpClient := &http.Client{
 		Timeout: 15 * time.Second,
@@ -65,104 +93,146 @@ func NewNacosConfigManager(localConfig *Config) (*NacosConfigManager, error) {
 		}
 	}
 
-	manager := &NacosConfigManager{
-		httpClient: httpClient,
-		config:     localConfig,
-		updateChan: make(chan *Config, 1),
-		stopChan:   make(chan struct{}),
+	listenClient := &http.Client{
+		Timeout:   nacosLongPollTimeout + 15*time.Second,
+		Transport: httpClient.Transport,
 	}
 
-	// 初始加载配置
-	if err := manager.loadConfig(); err != nil {
-		slog.Warn("初始配置加载失败，将使用本地配置", "error", err)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	manager := &NacosConfigManager{
+		httpClient:   httpClient,
+		configHolder: newConfigHolder(localConfig),
+		listenClient: listenClient,
+		stopChan:     make(chan struct{}),
+		ctx:          ctx,
+		cancel:       cancel,
 	}
 
-	// 启动配置轮询
-	go manager.startPolling()
+	// 确定配置API版本（auto时根据服务端版本自动检测）
+	manager.apiVersion = manager.resolveAPIVersion(localConfig.NacosAPIVersion)
 
-	return manager, nil
+	return manager
 }
 
 // loadConfig 通过 HTTP API 加载配置
 func (m *NacosConfigManager) loadConfig() error {
+	content, err := m.fetchContent()
+	if err != nil {
+		return err
+	}
+
+	m.contentMD5 = contentMD5(content)
+
+	// 解析配置，保留本地连接配置
+	return m.applyContent("nacos", content)
+}
+
+// fetchContent 获取配置原始内容
+func (m *NacosConfigManager) fetchContent() (string, error) {
 	// 确保有有效的访问令牌
 	if err := m.ensureValidToken(); err != nil {
-		return fmt.Errorf("获取访问令牌失败: %w", err)
+		return "", fmt.Errorf("获取访问令牌失败: %w", err)
 	}
 
 	// 获取配置
 	content, err := m.getConfig()
 	if err != nil {
-		return fmt.Errorf("获取配置失败: %w", err)
+		return "", fmt.Errorf("获取配置失败: %w", err)
 	}
+	return content, nil
+}
+
+// startListening 通过长轮询监听配置变化，监听失败时回退到定期拉取
+func (m *NacosConfigManager) startListening() {
+	slog.Info("启动Nacos配置监听", "long_poll_timeout", nacosLongPollTimeout, "fallback_interval", nacosPollInterval)
 
-	// 解析配置
-	var nacosConfig Config
-	if err := yaml.Unmarshal([]byte(content), &nacosConfig); err != nil {
-		return fmt.Errorf("解析配置失败: %w", err)
+	for {
+		select {
+		case <-m.stopChan:
+			slog.Info("停止Nacos配置监听")
+			return
+		default:
+		}
+
+		changed, err := m.listen()
+		if err != nil {
+			if m.ctx.Err() != nil {
+				slog.Info("停止Nacos配置监听")
+				return
+			}
+			slog.Debug("Nacos长轮询失败，回退到定期拉取", "error", err)
+			if err := m.loadConfig(); err != nil {
+				slog.Debug("配置拉取失败", "error", err)
+			}
+			m.wait(nacosPollInterval)
+			continue
+		}
+
+		if changed {
+			slog.Debug("Nacos通知配置已变化，重新加载")
+			if err := m.loadConfig(); err != nil {
+				slog.Debug("配置加载失败", "error", err)
+				m.wait(nacosPollInterval)
+			}
+		}
 	}
+}
 
-	// 保留原始的Nacos连接配置
-	nacosConfig.NacosUrl = m.config.NacosUrl
-	nacosConfig.Username = m.config.Username
-	nacosConfig.Password = m.config.Password
-	nacosConfig.NamespaceId = m.config.NamespaceId
-	nacosConfig.DataId = m.config.DataId
-	nacosConfig.Group = m.config.Group
+// listen 发起一次长轮询，返回配置是否发生变化
+func (m *NacosConfigManager) listen() (bool, error) {
+	if err := m.ensureValidToken(); err != nil {
+		return false, fmt.Errorf("获取访问令牌失败: %w", err)
+	}
 
-	// 应用默认值
-	applyDefaults(&nacosConfig)
+	// Listening-Configs格式: dataId^2group^2contentMD5^2tenant^1
+	listeningConfigs := strings.Join([]string{m.config.DataId, m.config.Group, m.contentMD5, m.config.NamespaceId}, "\x02") + "\x01"
+	form := url.Values{"Listening-Configs": {listeningConfigs}}
 
-	// 更新配置
-	m.configMutex.Lock()
-	oldConfig := m.config
-	m.config = &nacosConfig
-	m.configMutex.Unlock()
+	listenURL := fmt.Sprintf("%s/nacos/v1/cs/configs/listener?accessToken=%s", m.config.NacosUrl, url.QueryEscape(m.accessToken))
+	req, err := http.NewRequestWithContext(m.ctx, http.MethodPost, listenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("创建监听请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Long-Pulling-Timeout", strconv.Itoa(int(nacosLongPollTimeout.Milliseconds())))
 
-	// 检查配置是否有变化
-	configChanged := oldConfig == nil || 
-		len(oldConfig.Domains) != len(nacosConfig.Domains) ||
-		oldConfig.CheckInterval != nacosConfig.CheckInterval ||
-		oldConfig.Timeout != nacosConfig.Timeout
+	resp, err := m.listenClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("监听请求失败: %w", err)
+	}
+	defer resp.Body.Close()
 
-	if configChanged {
-		slog.Info("Nacos配置已更新", 
-			"domain_count", len(nacosConfig.Domains),
-			"check_interval", nacosConfig.CheckInterval,
-			"timeout", nacosConfig.Timeout)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("读取监听响应失败: %w", err)
+	}
 
-		// 通知配置更新
-		select {
-		case m.updateChan <- &nacosConfig:
-			slog.Debug("已发送配置更新通知")
-		default:
-			slog.Debug("配置更新通道已满，跳过通知")
-		}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("监听请求失败: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
-	return nil
+	// 配置未变化时服务端在超时后返回空内容
+	return strings.TrimSpace(string(body)) != "", nil
 }
 
-// startPolling 启动配置轮询（每10秒检查一次）
-func (m *NacosConfigManager) startPolling() {
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+// wait 等待指定时间，管理器关闭时立即返回
+func (m *NacosConfigManager) wait(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-	slog.Info("启动Nacos配置轮询", "interval", "10s")
-
-	for {
-		select {
-		case <-ticker.C:
-			if err := m.loadConfig(); err != nil {
-				slog.Debug("配置轮询失败", "error", err)
-			}
-		case <-m.stopChan:
-			slog.Info("停止Nacos配置轮询")
-			return
-		}
+	select {
+	case <-timer.C:
+	case <-m.stopChan:
 	}
 }
 
+// contentMD5 计算配置内容的MD5
+func contentMD5(content string) string {
+	sum := md5.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
 // ensureValidToken 确保有有效的访问令牌
 func (m *NacosConfigManager) ensureValidToken() error {
 	// 检查令牌是否过期（提前30秒刷新）
@@ -172,20 +242,25 @@ func (m *NacosConfigManager) ensureValidToken() error {
 	return nil
 }
 
-// refreshToken 刷新访问令牌
+// refreshToken 刷新访问令牌（v1登录接口不可用时尝试Nacos 3.x的v3登录接口）
 func (m *NacosConfigManager) refreshToken() error {
-	loginURL := fmt.Sprintf("%s/nacos/v1/auth/login", m.config.NacosUrl)
-	loginData := fmt.Sprintf("username=%s&password=%s", m.config.Username, m.config.Password)
-	
-	resp, err := m.httpClient.Post(loginURL, "application/x-www-form-urlencoded", strings.NewReader(loginData))
-	if err != nil {
-		return fmt.Errorf("登录请求失败: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("读取登录响应失败: %w", err)
+	loginData := url.Values{"username": {m.config.Username}, "password": {m.config.Password}}.Encode()
+
+	var body []byte
+	for _, loginPath := range []string{"/nacos/v1/auth/login", "/nacos/v3/auth/user/login"} {
+		resp, err := m.httpClient.Post(m.config.NacosUrl+loginPath, "application/x-www-form-urlencoded", strings.NewReader(loginData))
+		if err != nil {
+			return fmt.Errorf("登录请求失败: %w", err)
+		}
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("读取登录响应失败: %w", err)
+		}
+		if resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusGone {
+			break
+		}
+		slog.Debug("Nacos登录接口不可用，尝试下一个", "path", loginPath, "status", resp.StatusCode)
 	}
 	
 	var loginResp map[string]interface{}
@@ -211,10 +286,28 @@ func (m *NacosConfigManager) refreshToken() error {
 	return nil
 }
 
-// getConfig 获取配置内容
+// getConfig 按API版本获取配置内容，v2接口不可用时回退到v1
 func (m *NacosConfigManager) getConfig() (string, error) {
-	configURL := fmt.Sprintf("%s/nacos/v1/cs/configs?dataId=%s&group=%s&tenant=%s&accessToken=%s",
-		m.config.NacosUrl, m.config.DataId, m.config.Group, m.config.NamespaceId, m.accessToken)
+	if m.apiVersion == "v2" {
+		content, err := m.getConfigV2()
+		if !errors.Is(err, errNacosAPIUnavailable) {
+			return content, err
+		}
+		slog.Warn("Nacos v2配置接口不可用，回退到v1接口")
+		m.apiVersion = "v1"
+	}
+	return m.getConfigV1()
+}
+
+// getConfigV1 通过 /nacos/v1/cs/configs 获取配置内容
+func (m *NacosConfigManager) getConfigV1() (string, error) {
+	query := url.Values{
+		"dataId":      {m.config.DataId},
+		"group":       {m.config.Group},
+		"tenant":      {m.config.NamespaceId},
+		"accessToken": {m.accessToken},
+	}
+	configURL := fmt.Sprintf("%s/nacos/v1/cs/configs?%s", m.config.NacosUrl, query.Encode())
 	
 	resp, err := m.httpClient.Get(configURL)
 	if err != nil {
@@ -235,30 +328,124 @@ func (m *NacosConfigManager) getConfig() (string, error) {
 	return content, nil
 }
 
-// GetConfig 获取当前配置
-func (m *NacosConfigManager) GetConfig() *Config {
-	if m == nil {
-		return nil
+// errNacosAPIUnavailable 服务端不支持所请求的API版本
+var errNacosAPIUnavailable = errors.New("Nacos API不可用")
+
+// nacosV2Response Nacos v2 Open API统一响应格式
+type nacosV2Response struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// getConfigV2 通过 /nacos/v2/cs/config 获取配置内容
+func (m *NacosConfigManager) getConfigV2() (string, error) {
+	query := url.Values{
+		"dataId":      {m.config.DataId},
+		"group":       {m.config.Group},
+		"namespaceId": {m.config.NamespaceId},
+		"accessToken": {m.accessToken},
 	}
-	
-	m.configMutex.RLock()
-	defer m.configMutex.RUnlock()
-	return m.config
+	configURL := fmt.Sprintf("%s/nacos/v2/cs/config?%s", m.config.NacosUrl, query.Encode())
+
+	resp, err := m.httpClient.Get(configURL)
+	if err != nil {
+		return "", fmt.Errorf("获取配置请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("读取配置响应失败: %w", err)
+	}
+
+	var v2Resp nacosV2Response
+	if err := json.Unmarshal(body, &v2Resp); err != nil {
+		// 1.x服务端没有v2接口，返回404页面
+		if resp.StatusCode == http.StatusNotFound {
+			return "", errNacosAPIUnavailable
+		}
+		return "", fmt.Errorf("解析配置响应失败: HTTP %d: %w", resp.StatusCode, err)
+	}
+
+	if v2Resp.Code != 0 {
+		// 20004: 配置不存在
+		if v2Resp.Code == 20004 {
+			return "", fmt.Errorf("配置不存在或为空")
+		}
+		return "", fmt.Errorf("获取配置失败: code=%d, message=%s", v2Resp.Code, v2Resp.Message)
+	}
+
+	var content string
+	if err := json.Unmarshal(v2Resp.Data, &content); err != nil {
+		return "", fmt.Errorf("解析配置内容失败: %w", err)
+	}
+	if content == "" {
+		return "", fmt.Errorf("配置不存在或为空")
+	}
+
+	return content, nil
+}
+
+// resolveAPIVersion 确定使用的配置API版本，auto时根据服务端版本检测（2.x及以上使用v2）
+func (m *NacosConfigManager) resolveAPIVersion(configured string) string {
+	switch strings.ToLower(configured) {
+	case "v1":
+		return "v1"
+	case "v2":
+		return "v2"
+	}
+
+	version, err := m.serverVersion()
+	if err != nil {
+		// 无法检测时优先尝试v2，接口不可用会自动回退到v1
+		slog.Info("无法检测Nacos服务端版本，优先使用v2接口", "error", err)
+		return "v2"
+	}
+
+	apiVersion := "v1"
+	if major, _, _ := strings.Cut(version, "."); major != "" && major != "0" && major != "1" {
+		apiVersion = "v2"
+	}
+	slog.Info("检测到Nacos服务端版本", "version", version, "api_version", apiVersion)
+	return apiVersion
 }
 
-// GetUpdateChannel 获取配置更新通道
-func (m *NacosConfigManager) GetUpdateChannel() <-chan *Config {
-	if m == nil {
-		return nil
+// serverVersion 通过 /nacos/v1/console/server/state 获取服务端版本
+func (m *NacosConfigManager) serverVersion() (string, error) {
+	resp, err := m.httpClient.Get(m.config.NacosUrl + "/nacos/v1/console/server/state")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var state struct {
+		Version string `json:"version"`
 	}
-	return m.updateChan
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return "", err
+	}
+	if state.Version == "" {
+		return "", fmt.Errorf("响应中没有版本信息")
+	}
+	return state.Version, nil
+}
+
+// Name 配置源名称
+func (m *NacosConfigManager) Name() string {
+	return "nacos"
 }
 
 // Close 关闭Nacos配置管理器
 func (m *NacosConfigManager) Close() {
 	if m != nil {
+		m.cancel()
 		close(m.stopChan)
-		close(m.updateChan)
+		// 不关闭updateChan：监听协程可能仍在发送，消费方通过自身的ctx退出
 		slog.Info("Nacos配置管理器已关闭")
 	}
 }
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeNacos 模拟Nacos服务端，未设置的接口返回404
+type fakeNacos struct {
+	mutex    sync.Mutex
+	version  string            // /nacos/v1/console/server/state返回的版本，为空时返回500
+	v1Login  bool              // 是否提供v1登录接口
+	v3Login  bool              // 是否提供v3登录接口
+	v1Config string            // v1配置接口返回的内容
+	v2       func() (int, any) // v2配置接口的响应，为nil时返回404页面
+	requests []string          // 收到的请求路径和参数
+}
+
+func (f *fakeNacos) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	f.requests = append(f.requests, r.URL.Path+"?"+r.URL.RawQuery)
+	f.mutex.Unlock()
+
+	switch {
+	case r.URL.Path == "/nacos/v1/console/server/state" && f.version != "":
+		json.NewEncoder(w).Encode(map[string]string{"version": f.version})
+	case r.URL.Path == "/nacos/v1/console/server/state":
+		w.WriteHeader(http.StatusInternalServerError)
+	case r.URL.Path == "/nacos/v1/auth/login" && f.v1Login,
+		r.URL.Path == "/nacos/v3/auth/user/login" && f.v3Login:
+		json.NewEncoder(w).Encode(map[string]any{"accessToken": "token-" + strings.Split(r.URL.Path, "/")[2], "tokenTtl": 600})
+	case r.URL.Path == "/nacos/v1/cs/configs":
+		w.Write([]byte(f.v1Config))
+	case r.URL.Path == "/nacos/v2/cs/config" && f.v2 != nil:
+		status, body := f.v2()
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	default:
+		http.Error(w, "<html><body>404 Not Found</body></html>", http.StatusNotFound)
+	}
+}
+
+// lastRequest 获取最后一次请求指定路径的记录
+func (f *fakeNacos) lastRequest(path string) string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for i := len(f.requests) - 1; i >= 0; i-- {
+		if strings.HasPrefix(f.requests[i], path+"?") {
+			return f.requests[i]
+		}
+	}
+	return ""
+}
+
+func newFakeNacosClient(t *testing.T, fake *fakeNacos, apiVersion string) *NacosConfigManager {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	manager := newNacosClient(&Config{
+		NacosUrl:        server.URL,
+		NamespaceId:     "prod",
+		Username:        "nacos",
+		Password:        "secret",
+		DataId:          "domain-exporter.yml",
+		Group:           "DEFAULT_GROUP",
+		NacosAPIVersion: apiVersion,
+	})
+	t.Cleanup(manager.cancel)
+	return manager
+}
+
+func TestNacosResolveAPIVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		version    string
+		want       string
+	}{
+		{name: "1.x使用v1", configured: "auto", version: "1.4.1", want: "v1"},
+		{name: "2.x使用v2", configured: "auto", version: "2.3.2", want: "v2"},
+		{name: "3.x使用v2", configured: "", version: "3.0.1", want: "v2"},
+		{name: "无法检测时使用v2", configured: "auto", want: "v2"},
+		{name: "显式配置v1", configured: "v1", version: "2.3.2", want: "v1"},
+		{name: "显式配置v2", configured: "V2", version: "1.4.1", want: "v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newFakeNacosClient(t, &fakeNacos{version: tt.version}, tt.configured)
+			if manager.apiVersion != tt.want {
+				t.Errorf("apiVersion = %q, want %q", manager.apiVersion, tt.want)
+			}
+		})
+	}
+}
+
+func TestNacosGetConfigV2(t *testing.T) {
+	fake := &fakeNacos{v2: func() (int, any) {
+		return http.StatusOK, map[string]any{"code": 0, "message": "success", "data": "domains: [example.com]"}
+	}}
+	manager := newFakeNacosClient(t, fake, "v2")
+	manager.accessToken = "token"
+
+	content, err := manager.getConfig()
+	if err != nil {
+		t.Fatalf("getConfig() error = %v", err)
+	}
+	if content != "domains: [example.com]" {
+		t.Errorf("content = %q", content)
+	}
+	request := fake.lastRequest("/nacos/v2/cs/config")
+	for _, param := range []string{"namespaceId=prod", "dataId=domain-exporter.yml", "accessToken=token"} {
+		if !strings.Contains(request, param) {
+			t.Errorf("v2请求 %q 缺少参数 %s", request, param)
+		}
+	}
+}
+
+func TestNacosGetConfigV2FallbackToV1(t *testing.T) {
+	fake := &fakeNacos{v1Config: "domains: [example.com]"}
+	manager := newFakeNacosClient(t, fake, "v2")
+
+	content, err := manager.getConfig()
+	if err != nil {
+		t.Fatalf("getConfig() error = %v", err)
+	}
+	if content != "domains: [example.com]" {
+		t.Errorf("content = %q", content)
+	}
+	if manager.apiVersion != "v1" {
+		t.Errorf("apiVersion = %q, want v1 after fallback", manager.apiVersion)
+	}
+	if request := fake.lastRequest("/nacos/v1/cs/configs"); !strings.Contains(request, "tenant=prod") {
+		t.Errorf("v1请求 %q 缺少tenant参数", request)
+	}
+}
+
+func TestNacosGetConfigV2Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    any
+		wantErr string
+	}{
+		{name: "配置不存在", status: http.StatusNotFound, body: map[string]any{"code": 20004, "message": "config data not exist"}, wantErr: "配置不存在"},
+		{name: "其他错误码", status: http.StatusForbidden, body: map[string]any{"code": 403, "message": "authorization failed"}, wantErr: "code=403"},
+		{name: "内容为空", status: http.StatusOK, body: map[string]any{"code": 0, "data": ""}, wantErr: "配置不存在"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeNacos{v1Config: "v1 content", v2: func() (int, any) { return tt.status, tt.body }}
+			manager := newFakeNacosClient(t, fake, "v2")
+
+			_, err := manager.getConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("getConfig() error = %v, want %q", err, tt.wantErr)
+			}
+			// v2接口可用，不应回退到v1
+			if manager.apiVersion != "v2" {
+				t.Errorf("apiVersion = %q, want v2", manager.apiVersion)
+			}
+		})
+	}
+}
+
+func TestNacosGetConfigV1NotExist(t *testing.T) {
+	manager := newFakeNacosClient(t, &fakeNacos{v1Config: "config data not exist"}, "v1")
+	if _, err := manager.getConfig(); err == nil || !strings.Contains(err.Error(), "配置不存在") {
+		t.Fatalf("getConfig() error = %v, want not exist", err)
+	}
+}
+
+func TestNacosRefreshToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		fake      *fakeNacos
+		wantToken string
+		wantErr   bool
+	}{
+		{name: "v1登录", fake: &fakeNacos{v1Login: true, v3Login: true}, wantToken: "token-v1"},
+		{name: "v1不可用时使用v3登录", fake: &fakeNacos{v3Login: true}, wantToken: "token-v3"},
+		{name: "登录接口均不可用", fake: &fakeNacos{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newFakeNacosClient(t, tt.fake, "v1")
+
+			err := manager.refreshToken()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("refreshToken() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("refreshToken() error = %v", err)
+			}
+			if manager.accessToken != tt.wantToken {
+				t.Errorf("accessToken = %q, want %q", manager.accessToken, tt.wantToken)
+			}
+			if remaining := time.Until(manager.tokenExpiry); remaining <= 0 || remaining > 600*time.Second {
+				t.Errorf("tokenExpiry in %v, want within tokenTtl of 600s", remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// probeFailureCacheTTL 查询失败结果的缓存时间，避免抓取失败时频繁查询WHOIS
+const probeFailureCacheTTL = time.Minute
+
+// probeMaxEntries 缓存的目标数量上限，防止任意target请求占用内存
+const probeMaxEntries = 10000
+
+// probeDomainSource /probe输出的domain_info的source标签值
+const probeDomainSource = "probe"
+
+// probeTimeoutOffset 从Prometheus抓取超时中预留的时间，用于输出指标（与blackbox_exporter的--timeout-offset一致）
+const probeTimeoutOffset = 500 * time.Millisecond
+
+// probeEntry 单个目标的缓存结果
+type probeEntry struct {
+	mutex    sync.Mutex
+	result   probeResult
+	inflight chan struct{} // 进行中的查询（同一目标同时只发起一次），完成时关闭
+}
+
+// probeResult 目标最近一次查询结果，查询失败时info保留上次成功获取的域名信息
+type probeResult struct {
+	info        *DomainInfo
+	err         error
+	checkedAt   time.Time
+	lastSuccess time.Time
+	lifecycle   string             // 最近确定的生命周期状态
+	errors      map[string]float64 // 按失败原因统计的失败次数
+}
+
+// ProbeHandler blackbox-exporter风格的按需检查接口：/probe?target=example.com
+type ProbeHandler struct {
+	exporter *DomainExporter
+
+	mutex     sync.Mutex
+	entries   map[string]*probeEntry
+	lastEvict time.Time
+}
+
+// NewProbeHandler 创建按需检查处理器
+func NewProbeHandler(exporter *DomainExporter) *ProbeHandler {
+	return &ProbeHandler{
+		exporter: exporter,
+		entries:  make(map[string]*probeEntry),
+	}
+}
+
+// ServeHTTP 处理按需检查请求，返回该目标独立的指标
+func (h *ProbeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := strings.ToLower(strings.Trim(strings.TrimSpace(r.URL.Query().Get("target")), "."))
+	if target == "" {
+		http.Error(w, "target参数不能为空", http.StatusBadRequest)
+		return
+	}
+	if err := validateDomainName(target); err != nil {
+		http.Error(w, fmt.Sprintf("无效的target: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	ctx, cancel := scrapeContext(r)
+	defer cancel()
+	entry, err := h.entry(target)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	result := h.lookup(ctx, entry, target)
+
+	registry := prometheus.NewRegistry()
+	expiryDays := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_expiry_days",
+		Help: "域名距离过期的天数（检查失败时保留上次成功获取的值）",
+	}, []string{"domain"})
+	expiryTime := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_expiry_timestamp",
+		Help: "域名过期时间戳",
+	}, []string{"domain"})
+	checkTime := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_check_timestamp",
+		Help: "域名最后检查时间戳",
+	}, []string{"domain"})
+	status := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_check_status",
+		Help: "域名检查状态 (1=成功, 0=失败)",
+	}, []string{"domain"})
+	dataAge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_expiry_data_age_seconds",
+		Help: "距离上次成功获取域名过期时间的秒数",
+	}, []string{"domain"})
+	checkErrors := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "domain_check_errors_total",
+		Help: "域名检查失败次数，按失败原因分类（timeout, connect_refused, network, rate_limited, parse_failed, not_found, other）",
+	}, []string{"domain", "reason"})
+	lifecycle := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_lifecycle_state",
+		Help: "域名生命周期状态（当前状态为1，其他为0）：registered, expired, redemption_period, pending_delete, available",
+	}, []string{"domain", "state"})
+	statusCode := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_status_code",
+		Help: "域名当前的EPP状态码（值恒为1），如clientTransferProhibited、serverDeleteProhibited、clientHold",
+	}, []string{"domain", "code"})
+	transferLock := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_transfer_locked",
+		Help: "域名是否禁止转移（存在clientTransferProhibited或serverTransferProhibited，1=是, 0=否）",
+	}, []string{"domain"})
+	registryLock := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_registry_locked",
+		Help: "域名是否开启注册局锁定（同时存在serverDeleteProhibited、serverTransferProhibited、serverUpdateProhibited，1=是, 0=否）",
+	}, []string{"domain"})
+	domainInfo := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_info",
+		Help: domainInfoHelp,
+	}, domainInfoLabels)
+	createdTime := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_created_timestamp",
+		Help: "域名注册时间戳",
+	}, []string{"domain"})
+	updatedTime := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "domain_updated_timestamp",
+		Help: "域名注册信息最后更新时间戳",
+	}, []string{"domain"})
+	probeDuration := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "probe_duration_seconds",
+		Help: "本次按需检查耗时（秒），命中缓存时接近0",
+	})
+	registry.MustRegister(expiryDays, expiryTime, checkTime, status, dataAge, checkErrors, lifecycle, statusCode, transferLock, registryLock, domainInfo, createdTime, updatedTime, probeDuration)
+
+	checkTime.WithLabelValues(target).Set(float64(result.checkedAt.Unix()))
+	if result.err != nil {
+		status.WithLabelValues(target).Set(0)
+	} else {
+		status.WithLabelValues(target).Set(1)
+	}
+
+	// 与DomainExporter一致：失败时保留上次成功获取的值，兼容模式下写入-999/0哨兵值
+	if result.err != nil && h.exporter.getCurrentConfig().LegacyFailureSentinel {
+		expiryDays.WithLabelValues(target).Set(-999)
+		expiryTime.WithLabelValues(target).Set(0)
+	} else if result.info != nil {
+		expiryDays.WithLabelValues(target).Set(float64(int(time.Until(result.info.ExpiryDate).Hours() / 24)))
+		expiryTime.WithLabelValues(target).Set(float64(result.info.ExpiryDate.Unix()))
+	}
+	if !result.lastSuccess.IsZero() {
+		dataAge.WithLabelValues(target).Set(time.Since(result.lastSuccess).Seconds())
+	}
+	for reason, count := range result.errors {
+		checkErrors.WithLabelValues(target, reason).Add(count)
+	}
+	if result.lifecycle != "" {
+		for _, state := range lifecycleStates {
+			value := 0.0
+			if state == result.lifecycle {
+				value = 1
+			}
+			lifecycle.WithLabelValues(target, state).Set(value)
+		}
+	}
+	// 与DomainExporter一致：domain_info始终输出，注册商信息从未成功获取或域名未注册时为空字符串
+	registrarValues := []string{"", "", "", ""}
+	if info := result.info; info != nil && result.lifecycle != lifecycleAvailable {
+		registrarValues = []string{info.Registrar, info.RegistrarIANAID, info.WhoisServer, info.Method}
+	}
+	domainInfo.WithLabelValues(append([]string{target, probeDomainSource}, registrarValues...)...).Set(1)
+
+	// 状态码和注册信息使用上次成功获取的值，域名未注册时不输出
+	if info := result.info; info != nil && result.lifecycle != lifecycleAvailable {
+		if !info.CreatedDate.IsZero() {
+			createdTime.WithLabelValues(target).Set(float64(info.CreatedDate.Unix()))
+		}
+		if !info.UpdatedDate.IsZero() {
+			updatedTime.WithLabelValues(target).Set(float64(info.UpdatedDate.Unix()))
+		}
+		if codes := statusCodes(info.Statuses); len(codes) > 0 {
+			for _, code := range codes {
+				statusCode.WithLabelValues(target, code).Set(1)
+			}
+			locked := 0.0
+			if transferLocked(codes) {
+				locked = 1
+			}
+			transferLock.WithLabelValues(target).Set(locked)
+			locked = 0
+			if registryLocked(codes) {
+				locked = 1
+			}
+			registryLock.WithLabelValues(target).Set(locked)
+		}
+	}
+	probeDuration.Set(time.Since(start).Seconds())
+
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// entry 获取目标的缓存条目，新建条目前清理已过期的条目
+func (h *ProbeHandler) entry(target string) (*probeEntry, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if entry, ok := h.entries[target]; ok {
+		return entry, nil
+	}
+
+	now := time.Now()
+	if now.Sub(h.lastEvict) >= probeFailureCacheTTL || len(h.entries) >= probeMaxEntries {
+		h.evictExpired(now)
+	}
+	if len(h.entries) >= probeMaxEntries {
+		return nil, fmt.Errorf("按需检查的目标数量已达上限(%d)", probeMaxEntries)
+	}
+
+	entry := &probeEntry{}
+	h.entries[target] = entry
+	return entry, nil
+}
+
+// evictExpired 删除缓存已过期且没有进行中查询的条目（调用方需持有h.mutex）
+func (h *ProbeHandler) evictExpired(now time.Time) {
+	h.lastEvict = now
+	ttl := time.Duration(h.exporter.getCurrentConfig().CheckInterval) * time.Second
+
+	evicted := 0
+	for target, entry := range h.entries {
+		entry.mutex.Lock()
+		expired := entry.inflight == nil && !entry.result.checkedAt.IsZero() && now.Sub(entry.result.checkedAt) >= ttl
+		entry.mutex.Unlock()
+		if expired {
+			delete(h.entries, target)
+			evicted++
+		}
+	}
+	if evicted > 0 {
+		slog.Debug("清理过期的按需检查缓存", "evicted", evicted, "remaining", len(h.entries))
+	}
+}
+
+// scrapeContext 根据Prometheus的X-Prometheus-Scrape-Timeout-Seconds请求头设置等待查询结果的截止时间
+func scrapeContext(r *http.Request) (context.Context, context.CancelFunc) {
+	header := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+	if header == "" {
+		return context.WithCancel(r.Context())
+	}
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil || seconds <= 0 {
+		slog.DebugContext(r.Context(), "无效的抓取超时请求头", "value", header)
+		return context.WithCancel(r.Context())
+	}
+
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > probeTimeoutOffset {
+		timeout -= probeTimeoutOffset
+	}
+	return context.WithTimeout(r.Context(), timeout)
+}
+
+// lookup 获取目标信息：缓存未过期时直接返回缓存结果，否则发起查询并等待至抓取截止时间
+// 查询不受抓取请求取消的影响，未在截止时间前完成时结果仍会缓存，供下次抓取使用
+func (h *ProbeHandler) lookup(ctx context.Context, entry *probeEntry, target string) probeResult {
+	ctx = withLogDomain(ctx, target)
+	currentConfig := h.exporter.getCurrentConfig()
+
+	entry.mutex.Lock()
+	ttl := time.Duration(currentConfig.CheckInterval) * time.Second
+	if entry.result.err != nil {
+		ttl = probeFailureCacheTTL
+	}
+	if !entry.result.checkedAt.IsZero() && time.Since(entry.result.checkedAt) < ttl {
+		slog.DebugContext(ctx, "按需检查命中缓存", "domain", target, "checked_at", entry.result.checkedAt)
+		result := entry.result.snapshot()
+		entry.mutex.Unlock()
+		return result
+	}
+	if entry.inflight == nil {
+		entry.inflight = make(chan struct{})
+		go h.refresh(entry, target, entry.inflight)
+	}
+	done := entry.inflight
+	entry.mutex.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.WarnContext(ctx, "按需检查未在抓取超时前完成，结果将在下次抓取时返回", "domain", target)
+	}
+
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+
+	result := entry.result.snapshot()
+	if ctx.Err() != nil && entry.inflight == done {
+		result.err = fmt.Errorf("按需检查未在抓取超时前完成: %w", ErrLookupTimeout)
+		result.checkedAt = time.Now()
+	}
+	return result
+}
+
+// refresh 在独立于抓取请求的上下文中查询目标信息（超时由配置的timeout控制），完成后更新缓存
+func (h *ProbeHandler) refresh(entry *probeEntry, target string, done chan struct{}) {
+	currentConfig := h.exporter.getCurrentConfig()
+	lookupConfig := currentConfig.forDomain(currentConfig.DomainEntry(target))
+
+	ctx, cancel := context.WithTimeout(withLogDomain(h.exporter.ctx, target), time.Duration(lookupConfig.Timeout)*time.Second)
+	defer cancel()
+
+	slog.DebugContext(ctx, "按需检查域名", "domain", target)
+	info, err := GetDomainInfoWithFallback(ctx, target, lookupConfig)
+
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+	defer close(done)
+	entry.inflight = nil
+
+	// 监控已停止，不缓存结果
+	if err != nil && h.exporter.ctx.Err() != nil {
+		return
+	}
+
+	entry.result.err = err
+	entry.result.checkedAt = time.Now()
+	if err != nil {
+		reason := classifyCheckError(err)
+		slog.WarnContext(ctx, "按需检查失败", "domain", target, "reason", reason, "error", err)
+		if entry.result.errors == nil {
+			entry.result.errors = make(map[string]float64)
+		}
+		entry.result.errors[reason]++
+		if errors.Is(err, ErrDomainNotFound) {
+			entry.result.lifecycle = lifecycleAvailable
+		}
+	} else {
+		entry.result.info = info
+		entry.result.lastSuccess = entry.result.checkedAt
+		entry.result.lifecycle = info.State
+	}
+}
+
+// snapshot 复制查询结果，避免返回后与后续查询共享失败计数
+func (r probeResult) snapshot() probeResult {
+	counts := make(map[string]float64, len(r.errors))
+	for reason, count := range r.errors {
+		counts[reason] = count
+	}
+	r.errors = counts
+	return r
+}
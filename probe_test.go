@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProbeDomainInfoLabels(t *testing.T) {
+	provider := &stubProvider{name: "test-probe", info: &DomainInfo{
+		Domain:          "example.com",
+		ExpiryDate:      time.Now().AddDate(1, 0, 0),
+		Registrar:       "Example Registrar",
+		RegistrarIANAID: "1234",
+		WhoisServer:     "whois.example.net",
+		Method:          "rdap",
+	}}
+	RegisterProvider(provider.name, func(*Config) DomainInfoProvider { return provider })
+
+	config := &Config{Providers: ProviderConfig{Default: []string{provider.name}}}
+	applyDefaults(config)
+	exporter, err := NewDomainExporter(config)
+	if err != nil {
+		t.Fatalf("NewDomainExporter() error = %v", err)
+	}
+	defer exporter.Stop()
+
+	recorder := httptest.NewRecorder()
+	NewProbeHandler(exporter).ServeHTTP(recorder, httptest.NewRequest("GET", "/probe?target=example.com", nil))
+	body, _ := io.ReadAll(recorder.Result().Body)
+
+	// 与DomainExporter的domain_info使用相同的帮助信息和内置标签
+	want := `domain_info{domain="example.com",method="rdap",registrar="Example Registrar",registrar_iana_id="1234",source="probe",whois_server="whois.example.net"} 1`
+	if !strings.Contains(string(body), want) {
+		t.Errorf("probe输出缺少 %s:\n%s", want, body)
+	}
+	if !strings.Contains(string(body), "# HELP domain_info "+domainInfoHelp) {
+		t.Errorf("probe输出的domain_info帮助信息与DomainExporter不一致:\n%s", body)
+	}
+}
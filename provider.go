@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DomainInfoProvider 域名信息查询提供者
+type DomainInfoProvider interface {
+	// Name 提供者名称，与配置中的providers链对应
+	Name() string
+	// Lookup 查询域名信息
+	Lookup(ctx context.Context, domain string) (*DomainInfo, error)
+}
+
+// ProviderFactory 根据当前配置创建提供者
+// 传入Lookup的ctx在监控停止时取消，但不包含单次查询超时，提供者应在限速等待之后自行应用config.Timeout
+type ProviderFactory func(config *Config) DomainInfoProvider
+
+// errProviderNotApplicable 提供者不适用于该域名（如TLD不支持RDAP），直接尝试下一个提供者
+var errProviderNotApplicable = errors.New("提供者不适用于该域名")
+
+// defaultProviderChain 未配置providers时使用的查询链
+var defaultProviderChain = []string{"rdap", "whois", "manual"}
+
+var (
+	providerMutex     sync.RWMutex
+	providerFactories = map[string]ProviderFactory{
+		"rdap": func(config *Config) DomainInfoProvider {
+			return &rdapProvider{client: defaultRDAPClient, config: config}
+		},
+		"whois":  func(config *Config) DomainInfoProvider { return &whoisProvider{config: config} },
+		"manual": func(config *Config) DomainInfoProvider { return &manualProvider{expiry: config.ManualExpiry} },
+	}
+)
+
+// RegisterProvider 注册自定义提供者（如内部注册商API），注册后可在providers链中按名称引用
+func RegisterProvider(name string, factory ProviderFactory) {
+	providerMutex.Lock()
+	defer providerMutex.Unlock()
+	providerFactories[strings.ToLower(name)] = factory
+}
+
+// isProviderRegistered 判断提供者名称是否已注册
+func isProviderRegistered(name string) bool {
+	providerMutex.RLock()
+	defer providerMutex.RUnlock()
+	_, ok := providerFactories[strings.ToLower(name)]
+	return ok
+}
+
+// newProvider 按名称创建提供者
+func newProvider(name string, config *Config) (DomainInfoProvider, error) {
+	providerMutex.RLock()
+	factory, ok := providerFactories[strings.ToLower(name)]
+	providerMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的提供者: %s", name)
+	}
+	return factory(config), nil
+}
+
+// ProviderConfig 域名信息提供者链配置
+type ProviderConfig struct {
+	Default []string            `yaml:"default"` // 默认查询链，如 [rdap, whois, manual]
+	TLDs    map[string][]string `yaml:"tlds"`    // 按TLD覆盖查询链，如 cn: [whois]
+}
+
+// ProviderChain 获取域名使用的提供者链（按最长后缀匹配TLD配置）
+func (c *Config) ProviderChain(domain string) []string {
+	domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "."))
+
+	if len(c.Providers.TLDs) > 0 {
+		labels := strings.Split(domain, ".")
+		for i := 1; i < len(labels); i++ {
+			suffix := strings.Join(labels[i:], ".")
+			if chain, ok := c.Providers.TLDs[suffix]; ok && len(chain) > 0 {
+				return chain
+			}
+		}
+	}
+
+	if len(c.Providers.Default) > 0 {
+		return c.Providers.Default
+	}
+	return defaultProviderChain
+}
+
+// rdapProvider 基于RDAP的提供者
+type rdapProvider struct {
+	client *RDAPClient
+	config *Config
+}
+
+func (p *rdapProvider) Name() string { return "rdap" }
+
+func (p *rdapProvider) Lookup(ctx context.Context, domain string) (*DomainInfo, error) {
+	timeout := time.Duration(p.config.Timeout) * time.Second
+	tld := domainTLD(domain)
+
+	bootstrapCtx, cancel := context.WithTimeout(ctx, timeout)
+	baseURL, err := p.client.BaseURL(bootstrapCtx, tld)
+	cancel()
+	if errors.Is(err, errRDAPBootstrapUnavailable) {
+		// 引导文件不可用时直接回退到下一个提供者，不进行重试
+		slog.DebugContext(ctx, "RDAP引导文件不可用，跳过RDAP查询", "domain", domain, "error", err)
+		return nil, errProviderNotApplicable
+	}
+	if err != nil {
+		return nil, err
+	}
+	if baseURL == "" {
+		return nil, errProviderNotApplicable
+	}
+
+	// 按RDAP服务器限速
+	server := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		server = u.Host
+	}
+	if err := defaultRateLimiter.Wait(ctx, server, tld, p.config.RateLimit); err != nil {
+		return nil, fmt.Errorf("等待查询配额失败: %w", err)
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return p.client.Lookup(queryCtx, domain)
+}
+
+// whoisProvider 基于端口43 WHOIS的提供者
+type whoisProvider struct {
+	config *Config
+}
+
+func (p *whoisProvider) Name() string { return "whois" }
+
+func (p *whoisProvider) Lookup(ctx context.Context, domain string) (*DomainInfo, error) {
+	return GetDomainInfo(ctx, domain, p.config)
+}
+
+// manualProvider 使用配置中手动维护的过期时间（适用于没有公开WHOIS/RDAP的TLD）
+type manualProvider struct {
+	expiry map[string]string
+}
+
+func (p *manualProvider) Name() string { return "manual" }
+
+func (p *manualProvider) Lookup(ctx context.Context, domain string) (*DomainInfo, error) {
+	dateStr, ok := p.expiry[domain]
+	if !ok {
+		return nil, errProviderNotApplicable
+	}
+
+	expiryDate, err := parseFlexibleDate(dateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DomainInfo{
+		Domain:     domain,
+		ExpiryDate: expiryDate,
+		Registrar:  "Unknown",
+		Status:     "unknown",
+		Method:     "manual",
+	}, nil
+}
+
+// lookupWithRetry 使用单个提供者查询域名（带重试）
+func lookupWithRetry(ctx context.Context, provider DomainInfoProvider, domain string, maxRetries int) (*DomainInfo, error) {
+	var lastErr error
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		slog.DebugContext(ctx, "域名查询尝试", "domain", domain, "provider", provider.Name(), "attempt", attempt, "max_retries", maxRetries)
+
+		info, err := provider.Lookup(ctx, domain)
+		if err == nil {
+			if attempt > 1 {
+				slog.InfoContext(ctx, "域名查询重试成功", "domain", domain, "provider", provider.Name(), "attempt", attempt)
+			}
+			return info, nil
+		}
+
+		lastErr = err
+		if errors.Is(err, errProviderNotApplicable) || ctx.Err() != nil {
+			return nil, err
+		}
+		// 域名未注册、响应无法解析等错误重试也不会成功
+		if isPermanentLookupError(err) {
+			slog.DebugContext(ctx, "域名查询失败，错误不可重试", "domain", domain, "provider", provider.Name(), "attempt", attempt, "error", err)
+			return nil, err
+		}
+		slog.DebugContext(ctx, "域名查询失败", "domain", domain, "provider", provider.Name(), "attempt", attempt, "error", err)
+
+		// 如果不是最后一次尝试，等待一下再重试
+		if attempt < maxRetries {
+			waitTime := time.Duration(attempt) * time.Second
+			slog.DebugContext(ctx, "等待重试", "domain", domain, "wait_seconds", waitTime.Seconds())
+			select {
+			case <-time.After(waitTime):
+			case <-ctx.Done():
+				return nil, lastErr
+			}
+		}
+	}
+
+	return nil, lastErr
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RateLimitConfig 查询限速配置（按WHOIS/RDAP服务器限速）
+type RateLimitConfig struct {
+	QueriesPerMinute int                      `yaml:"queries_per_minute"` // 默认每分钟查询次数
+	Burst            int                      `yaml:"burst"`              // 默认突发数量
+	Servers          map[string]RateLimitRule `yaml:"servers"`            // 按服务器主机名或TLD覆盖，如 whois.cnnic.cn、cn
+}
+
+// RateLimitRule 单个服务器的限速规则
+type RateLimitRule struct {
+	QueriesPerMinute int `yaml:"queries_per_minute"`
+	Burst            int `yaml:"burst"`
+}
+
+// ruleFor 获取服务器的限速规则（服务器主机名优先，其次TLD，最后使用默认值）
+func (c RateLimitConfig) ruleFor(server, tld string) RateLimitRule {
+	rule := RateLimitRule{QueriesPerMinute: c.QueriesPerMinute, Burst: c.Burst}
+	if override, ok := c.Servers[server]; ok {
+		rule = override
+	} else if override, ok := c.Servers[tld]; ok {
+		rule = override
+	}
+
+	if rule.QueriesPerMinute <= 0 {
+		rule.QueriesPerMinute = c.QueriesPerMinute
+	}
+	if rule.Burst <= 0 {
+		rule.Burst = c.Burst
+	}
+	return rule
+}
+
+// tokenBucket 令牌桶
+type tokenBucket struct {
+	rule   RateLimitRule
+	tokens float64
+	last   time.Time
+}
+
+// reserve 预留一个令牌，返回需要等待的时间
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	ratePerSecond := float64(b.rule.QueriesPerMinute) / 60
+	b.tokens += now.Sub(b.last).Seconds() * ratePerSecond
+	if b.tokens > float64(b.rule.Burst) {
+		b.tokens = float64(b.rule.Burst)
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / ratePerSecond * float64(time.Second))
+}
+
+// RateLimiter 按服务器分桶的查询限速器
+type RateLimiter struct {
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+
+	waitsTotal       *prometheus.CounterVec
+	waitSecondsTotal *prometheus.CounterVec
+}
+
+// defaultRateLimiter 全局共享的限速器，所有提供者共用
+var defaultRateLimiter = NewRateLimiter()
+
+// NewRateLimiter 创建限速器
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		waitsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "domain_exporter_ratelimit_waits_total",
+				Help: "因限速而等待的查询次数",
+			},
+			[]string{"server"},
+		),
+		waitSecondsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "domain_exporter_ratelimit_wait_seconds_total",
+				Help: "因限速而等待的总时长（秒）",
+			},
+			[]string{"server"},
+		),
+	}
+}
+
+// Wait 等待服务器的查询配额，ctx取消或超时时返回包装了ErrRateLimited的错误
+func (l *RateLimiter) Wait(ctx context.Context, server, tld string, config RateLimitConfig) error {
+	rule := config.ruleFor(server, tld)
+	if rule.QueriesPerMinute <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	l.mutex.Lock()
+	bucket, ok := l.buckets[server]
+	if !ok || bucket.rule != rule {
+		bucket = &tokenBucket{rule: rule, tokens: float64(rule.Burst), last: now}
+		l.buckets[server] = bucket
+	}
+	wait := bucket.reserve(now)
+	l.mutex.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	slog.DebugContext(ctx, "查询限速等待", "server", server, "wait_seconds", wait.Seconds())
+	l.waitsTotal.WithLabelValues(server).Inc()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		l.waitSecondsTotal.WithLabelValues(server).Add(wait.Seconds())
+		return nil
+	case <-ctx.Done():
+		l.waitSecondsTotal.WithLabelValues(server).Add(time.Since(now).Seconds())
+		// 归还未使用的令牌
+		l.mutex.Lock()
+		bucket.tokens++
+		l.mutex.Unlock()
+		return fmt.Errorf("%w: %w", ErrRateLimited, ctx.Err())
+	}
+}
+
+// Describe 实现Prometheus Collector接口
+func (l *RateLimiter) Describe(ch chan<- *prometheus.Desc) {
+	l.waitsTotal.Describe(ch)
+	l.waitSecondsTotal.Describe(ch)
+}
+
+// Collect 实现Prometheus Collector接口
+func (l *RateLimiter) Collect(ch chan<- prometheus.Metric) {
+	l.waitsTotal.Collect(ch)
+	l.waitSecondsTotal.Collect(ch)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketBurstAndRefill(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	bucket := &tokenBucket{rule: RateLimitRule{QueriesPerMinute: 60, Burst: 3}, tokens: 3, last: start}
+
+	steps := []struct {
+		offset time.Duration
+		want   time.Duration
+	}{
+		// 突发数量内不等待
+		{offset: 0, want: 0},
+		{offset: 0, want: 0},
+		{offset: 0, want: 0},
+		// 令牌耗尽后按每秒1个的速率排队
+		{offset: 0, want: time.Second},
+		{offset: 0, want: 2 * time.Second},
+		// 5秒后补充5个令牌，抵消之前预留的2个后剩余3个（不超过突发数量）
+		{offset: 5 * time.Second, want: 0},
+		{offset: 5 * time.Second, want: 0},
+		{offset: 5 * time.Second, want: 0},
+		{offset: 5 * time.Second, want: time.Second},
+		// 很久之后令牌最多恢复到突发数量
+		{offset: time.Hour, want: 0},
+		{offset: time.Hour, want: 0},
+		{offset: time.Hour, want: 0},
+		{offset: time.Hour, want: time.Second},
+	}
+
+	for i, step := range steps {
+		if got := bucket.reserve(start.Add(step.offset)); got != step.want {
+			t.Errorf("step %d (t+%v): reserve() = %v, want %v", i, step.offset, got, step.want)
+		}
+	}
+}
+
+func TestRateLimiterPerServer(t *testing.T) {
+	limiter := NewRateLimiter()
+	config := RateLimitConfig{
+		QueriesPerMinute: 1,
+		Burst:            2,
+		Servers: map[string]RateLimitRule{
+			"whois.cnnic.cn": {QueriesPerMinute: 1, Burst: 1},
+		},
+	}
+
+	// 已取消的ctx：需要等待时立即返回ErrRateLimited，不会阻塞
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	wait := func(server, tld string) error {
+		return limiter.Wait(cancelled, server, tld, config)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := wait("whois.verisign-grs.com", "com"); err != nil {
+			t.Fatalf("第%d次查询在突发数量内，error = %v", i+1, err)
+		}
+	}
+	if err := wait("whois.verisign-grs.com", "com"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("超出突发数量后 error = %v, want ErrRateLimited", err)
+	}
+
+	// 其他服务器使用独立的令牌桶
+	if err := wait("whois.nic.io", "io"); err != nil {
+		t.Errorf("其他服务器的查询不应受影响，error = %v", err)
+	}
+
+	// 按服务器覆盖的突发数量
+	if err := wait("whois.cnnic.cn", "cn"); err != nil {
+		t.Errorf("whois.cnnic.cn 第1次查询 error = %v", err)
+	}
+	if err := wait("whois.cnnic.cn", "cn"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("whois.cnnic.cn 超出突发数量后 error = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestRateLimitRuleFor(t *testing.T) {
+	config := RateLimitConfig{
+		QueriesPerMinute: 30,
+		Burst:            3,
+		Servers: map[string]RateLimitRule{
+			"whois.cnnic.cn": {QueriesPerMinute: 5, Burst: 1},
+			"cn":             {QueriesPerMinute: 10},
+			"io":             {Burst: 5},
+		},
+	}
+
+	tests := []struct {
+		server string
+		tld    string
+		want   RateLimitRule
+	}{
+		{server: "whois.cnnic.cn", tld: "cn", want: RateLimitRule{QueriesPerMinute: 5, Burst: 1}},
+		{server: "whois.other.cn", tld: "cn", want: RateLimitRule{QueriesPerMinute: 10, Burst: 3}},
+		{server: "whois.nic.io", tld: "io", want: RateLimitRule{QueriesPerMinute: 30, Burst: 5}},
+		{server: "whois.verisign-grs.com", tld: "com", want: RateLimitRule{QueriesPerMinute: 30, Burst: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.server, func(t *testing.T) {
+			if got := config.ruleFor(tt.server, tt.tld); got != tt.want {
+				t.Errorf("ruleFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
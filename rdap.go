@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rdapBootstrapURL IANA发布的域名RDAP引导文件（TLD -> RDAP服务地址）
+const rdapBootstrapURL = "https://data.iana.org/rdap/dns.json"
+
+// rdapBootstrapTTL 引导文件缓存时间
+const rdapBootstrapTTL = 24 * time.Hour
+
+// rdapBootstrapRetryInterval 引导文件下载失败后的重试间隔，期间不再尝试下载
+const rdapBootstrapRetryInterval = 10 * time.Minute
+
+// errRDAPBootstrapUnavailable 引导文件不可用（下载失败且没有缓存）
+var errRDAPBootstrapUnavailable = errors.New("RDAP引导文件不可用")
+
+// RDAPClient RDAP查询客户端
+type RDAPClient struct {
+	httpClient   *http.Client
+	bootstrapURL string
+
+	refreshMutex sync.Mutex // 串行化引导文件下载，避免并发检查时重复下载
+
+	mutex     sync.RWMutex
+	services  map[string]string // TLD -> RDAP基础地址
+	fetchedAt time.Time
+	failedAt  time.Time // 最近一次下载失败的时间
+	lastErr   error     // 最近一次下载失败的错误
+}
+
+// defaultRDAPClient 全局共享的RDAP客户端，复用引导文件缓存
+var defaultRDAPClient = NewRDAPClient()
+
+// NewRDAPClient 创建RDAP客户端
+func NewRDAPClient() *RDAPClient {
+	return &RDAPClient{
+		httpClient:   &http.Client{},
+		bootstrapURL: rdapBootstrapURL,
+		services:     make(map[string]string),
+	}
+}
+
+// rdapBootstrapFile IANA引导文件结构
+type rdapBootstrapFile struct {
+	Services [][][]string `json:"services"`
+}
+
+// rdapDomainResponse RDAP域名查询响应（仅包含需要的字段）
+type rdapDomainResponse struct {
+	LDHName  string       `json:"ldhName"`
+	Status   []string     `json:"status"`
+	Events   []rdapEvent  `json:"events"`
+	Entities []rdapEntity `json:"entities"`
+	Port43   string       `json:"port43"` // 对应的WHOIS服务器
+}
+
+// rdapEvent RDAP事件
+type rdapEvent struct {
+	EventAction string `json:"eventAction"`
+	EventDate   string `json:"eventDate"`
+}
+
+// rdapEntity RDAP实体（注册商、联系人等）
+type rdapEntity struct {
+	Roles      []string        `json:"roles"`
+	VCardArray json.RawMessage `json:"vcardArray"`
+	PublicIDs  []rdapPublicID  `json:"publicIds"`
+}
+
+// rdapPublicID RDAP实体的公开标识（如注册商的IANA ID）
+type rdapPublicID struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
+}
+
+// BaseURL 获取TLD对应的RDAP基础地址，未收录时返回空字符串
+// 引导文件下载失败时继续使用过期的缓存；没有缓存时在重试间隔内直接返回errRDAPBootstrapUnavailable
+func (c *RDAPClient) BaseURL(ctx context.Context, tld string) (string, error) {
+	tld = strings.ToLower(strings.Trim(tld, "."))
+
+	if baseURL, ok, err := c.cachedBaseURL(tld); ok || err != nil {
+		return baseURL, err
+	}
+
+	c.refreshMutex.Lock()
+	defer c.refreshMutex.Unlock()
+
+	// 等待期间其他查询可能已完成下载
+	if baseURL, ok, err := c.cachedBaseURL(tld); ok || err != nil {
+		return baseURL, err
+	}
+
+	if err := c.refreshBootstrap(ctx); err != nil {
+		// 监控停止导致的取消不视为下载失败
+		if errors.Is(err, context.Canceled) {
+			return "", err
+		}
+
+		c.mutex.Lock()
+		c.failedAt = time.Now()
+		c.lastErr = err
+		stale := !c.fetchedAt.IsZero()
+		c.mutex.Unlock()
+
+		if !stale {
+			slog.WarnContext(ctx, "下载RDAP引导文件失败，暂时跳过RDAP查询", "retry_after", rdapBootstrapRetryInterval, "error", err)
+			return "", fmt.Errorf("%w: %w", errRDAPBootstrapUnavailable, err)
+		}
+		slog.WarnContext(ctx, "下载RDAP引导文件失败，继续使用过期的缓存", "retry_after", rdapBootstrapRetryInterval, "error", err)
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.services[tld], nil
+}
+
+// cachedBaseURL 从缓存获取RDAP基础地址，ok为false表示需要下载引导文件
+func (c *RDAPClient) cachedBaseURL(tld string) (baseURL string, ok bool, err error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < rdapBootstrapTTL {
+		return c.services[tld], true, nil
+	}
+	if !c.failedAt.IsZero() && time.Since(c.failedAt) < rdapBootstrapRetryInterval {
+		if c.fetchedAt.IsZero() {
+			return "", false, fmt.Errorf("%w: %w", errRDAPBootstrapUnavailable, c.lastErr)
+		}
+		return c.services[tld], true, nil
+	}
+	return "", false, nil
+}
+
+// refreshBootstrap 下载并解析IANA引导文件
+func (c *RDAPClient) refreshBootstrap(ctx context.Context) error {
+	slog.DebugContext(ctx, "下载RDAP引导文件", "url", c.bootstrapURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.bootstrapURL, nil)
+	if err != nil {
+		return fmt.Errorf("创建RDAP引导请求失败: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("获取RDAP引导文件失败: %w", wrapNetworkError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("获取RDAP引导文件失败: HTTP %d", resp.StatusCode)
+	}
+
+	var bootstrap rdapBootstrapFile
+	if err := json.NewDecoder(resp.Body).Decode(&bootstrap); err != nil {
+		return fmt.Errorf("解析RDAP引导文件失败: %w", err)
+	}
+
+	services := make(map[string]string)
+	for _, service := range bootstrap.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+		// 优先使用HTTPS地址
+		baseURL := service[1][0]
+		for _, u := range service[1] {
+			if strings.HasPrefix(u, "https://") {
+				baseURL = u
+				break
+			}
+		}
+		for _, tld := range service[0] {
+			services[strings.ToLower(tld)] = baseURL
+		}
+	}
+
+	c.mutex.Lock()
+	c.services = services
+	c.fetchedAt = time.Now()
+	c.mutex.Unlock()
+
+	slog.DebugContext(ctx, "RDAP引导文件已更新", "tld_count", len(services))
+	return nil
+}
+
+// Lookup 通过RDAP查询域名信息
+func (c *RDAPClient) Lookup(ctx context.Context, domain string) (*DomainInfo, error) {
+	baseURL, err := c.BaseURL(ctx, domainTLD(domain))
+	if err != nil {
+		return nil, err
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("TLD不支持RDAP: %s", domainTLD(domain))
+	}
+
+	queryURL := strings.TrimSuffix(baseURL, "/") + "/domain/" + domain
+	slog.DebugContext(ctx, "执行RDAP查询", "domain", domain, "url", queryURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, queryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建RDAP请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("RDAP查询失败: %w", wrapNetworkError(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取RDAP响应失败: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("RDAP查询失败: HTTP %d: %w", resp.StatusCode, ErrDomainNotFound)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("RDAP查询失败: HTTP %d: %w", resp.StatusCode, ErrRateLimited)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("RDAP查询失败: HTTP %d: %w", resp.StatusCode, ErrLookupNetwork)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("RDAP查询失败: HTTP %d", resp.StatusCode)
+	}
+
+	return parseRDAPResponse(ctx, domain, body)
+}
+
+// parseRDAPResponse 解析RDAP域名响应
+func parseRDAPResponse(ctx context.Context, domain string, body []byte) (*DomainInfo, error) {
+	var rdapResp rdapDomainResponse
+	if err := json.Unmarshal(body, &rdapResp); err != nil {
+		return nil, fmt.Errorf("解析RDAP响应失败: %w: %w", ErrInvalidResponse, err)
+	}
+
+	var expiryDate, createdDate, updatedDate time.Time
+	for _, event := range rdapResp.Events {
+		switch event.EventAction {
+		case "expiration":
+			if !expiryDate.IsZero() {
+				continue
+			}
+			date, err := time.Parse(time.RFC3339, event.EventDate)
+			if err != nil {
+				return nil, fmt.Errorf("%w: RDAP过期时间 %s", ErrUnparseableDate, event.EventDate)
+			}
+			expiryDate = date
+		case "registration":
+			// 注册和更新时间仅用于信息指标，无法解析时忽略
+			createdDate, _ = time.Parse(time.RFC3339, event.EventDate)
+		case "last changed":
+			updatedDate, _ = time.Parse(time.RFC3339, event.EventDate)
+		}
+	}
+
+	if expiryDate.IsZero() {
+		return nil, fmt.Errorf("RDAP响应中没有过期时间: %w", ErrMissingExpiry)
+	}
+
+	registrar := "Unknown"
+	var registrarIANAID string
+	for _, entity := range rdapResp.Entities {
+		if containsString(entity.Roles, "registrar") {
+			if name := vcardFullName(entity.VCardArray); name != "" {
+				registrar = name
+			}
+			for _, id := range entity.PublicIDs {
+				if strings.EqualFold(id.Type, "IANA Registrar ID") {
+					registrarIANAID = id.Identifier
+				}
+			}
+			break
+		}
+	}
+
+	status := "unknown"
+	if len(rdapResp.Status) > 0 {
+		status = rdapResp.Status[0]
+	}
+
+	slog.DebugContext(ctx, "RDAP解析成功", "domain", domain, "registrar", registrar, "expiry_date", expiryDate)
+
+	return &DomainInfo{
+		Domain:          domain,
+		ExpiryDate:      expiryDate,
+		CreatedDate:     createdDate,
+		UpdatedDate:     updatedDate,
+		Registrar:       registrar,
+		RegistrarIANAID: registrarIANAID,
+		WhoisServer:     rdapResp.Port43,
+		Status:          status,
+		Statuses:        rdapResp.Status,
+		Method:          "rdap",
+	}, nil
+}
+
+// vcardFullName 从jCard数组中提取fn字段
+func vcardFullName(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	// jCard格式: ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "名称"], ...]]
+	var vcard []json.RawMessage
+	if err := json.Unmarshal(raw, &vcard); err != nil || len(vcard) < 2 {
+		return ""
+	}
+
+	var properties [][]json.RawMessage
+	if err := json.Unmarshal(vcard[1], &properties); err != nil {
+		return ""
+	}
+
+	for _, property := range properties {
+		if len(property) < 4 {
+			continue
+		}
+		var name, value string
+		if json.Unmarshal(property[0], &name) != nil || name != "fn" {
+			continue
+		}
+		if json.Unmarshal(property[3], &value) == nil {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// domainTLD 获取域名的顶级域
+func domainTLD(domain string) string {
+	domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "."))
+	if idx := strings.LastIndex(domain, "."); idx >= 0 {
+		return domain[idx+1:]
+	}
+	return domain
+}
+
+// containsString 判断切片中是否包含指定字符串
+func containsString(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// rdapDomainJSON 典型的RDAP域名响应（参考Verisign的.com响应）
+const rdapDomainJSON = `{
+  "objectClassName": "domain",
+  "ldhName": "EXAMPLE.COM",
+  "status": ["client delete prohibited", "client transfer prohibited", "active"],
+  "port43": "whois.example-registrar.com",
+  "entities": [
+    {
+      "objectClassName": "entity",
+      "roles": ["registrar"],
+      "publicIds": [{"type": "IANA Registrar ID", "identifier": "292"}],
+      "vcardArray": ["vcard", [
+        ["version", {}, "text", "4.0"],
+        ["fn", {}, "text", "Example Registrar, Inc."]
+      ]]
+    }
+  ],
+  "events": [
+    {"eventAction": "registration", "eventDate": "1995-08-14T04:00:00Z"},
+    {"eventAction": "expiration", "eventDate": "2030-08-13T04:00:00Z"},
+    {"eventAction": "last changed", "eventDate": "2024-08-14T07:01:38Z"},
+    {"eventAction": "last update of RDAP database", "eventDate": "2025-01-01T00:00:00Z"}
+  ]
+}`
+
+// newFakeRDAPServer 按域名返回预设响应的HTTPS RDAP服务，同时提供引导文件
+// 引导文件中的HTTP地址不可用，用于验证优先使用HTTPS地址
+func newFakeRDAPServer(t *testing.T, responses map[string]func(w http.ResponseWriter)) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns.json" {
+			fmt.Fprintf(w, `{"services": [[["com", "net"], ["http://rdap.invalid/", "%s/"]]]}`, server.URL)
+			return
+		}
+		respond, ok := responses[strings.TrimPrefix(r.URL.Path, "/domain/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		respond(w)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRDAPProviderLookup(t *testing.T) {
+	respondJSON := func(status int, body string) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/rdap+json")
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+		}
+	}
+	server := newFakeRDAPServer(t, map[string]func(w http.ResponseWriter){
+		"example.com":      respondJSON(http.StatusOK, rdapDomainJSON),
+		"limited.com":      respondJSON(http.StatusTooManyRequests, `{"errorCode": 429}`),
+		"broken.com":       respondJSON(http.StatusBadGateway, `{"errorCode": 502}`),
+		"noexpiry.com":     respondJSON(http.StatusOK, `{"ldhName": "NOEXPIRY.COM", "events": [{"eventAction": "registration", "eventDate": "2020-01-01T00:00:00Z"}]}`),
+		"baddate.com":      respondJSON(http.StatusOK, `{"ldhName": "BADDATE.COM", "events": [{"eventAction": "expiration", "eventDate": "13/08/2030"}]}`),
+		"invalid.com":      respondJSON(http.StatusOK, `<html>maintenance</html>`),
+		"novcard.net":      respondJSON(http.StatusOK, `{"ldhName": "NOVCARD.NET", "events": [{"eventAction": "expiration", "eventDate": "2030-01-01T00:00:00Z"}], "entities": [{"roles": ["registrar"]}]}`),
+		"registrant.com":   respondJSON(http.StatusOK, `{"events": [{"eventAction": "expiration", "eventDate": "2030-01-01T00:00:00Z"}], "entities": [{"roles": ["registrant"], "vcardArray": ["vcard", [["fn", {}, "text", "Someone"]]]}]}`),
+		"unregistered.com": respondJSON(http.StatusNotFound, `{"errorCode": 404, "title": "Not Found"}`),
+	})
+
+	client := NewRDAPClient()
+	client.httpClient = server.Client()
+	client.bootstrapURL = server.URL + "/dns.json"
+	provider := &rdapProvider{client: client, config: &Config{Timeout: 5}}
+
+	tests := []struct {
+		domain  string
+		want    *DomainInfo
+		wantErr error
+	}{
+		{
+			domain: "example.com",
+			want: &DomainInfo{
+				Domain:          "example.com",
+				ExpiryDate:      time.Date(2030, 8, 13, 4, 0, 0, 0, time.UTC),
+				CreatedDate:     time.Date(1995, 8, 14, 4, 0, 0, 0, time.UTC),
+				UpdatedDate:     time.Date(2024, 8, 14, 7, 1, 38, 0, time.UTC),
+				Registrar:       "Example Registrar, Inc.",
+				RegistrarIANAID: "292",
+				WhoisServer:     "whois.example-registrar.com",
+				Status:          "client delete prohibited",
+				Statuses:        []string{"client delete prohibited", "client transfer prohibited", "active"},
+				Method:          "rdap",
+			},
+		},
+		{
+			domain: "novcard.net",
+			want: &DomainInfo{
+				Domain:     "novcard.net",
+				ExpiryDate: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+				Registrar:  "Unknown",
+				Status:     "unknown",
+				Method:     "rdap",
+			},
+		},
+		{
+			// 只从registrar角色的实体获取注册商
+			domain: "registrant.com",
+			want: &DomainInfo{
+				Domain:     "registrant.com",
+				ExpiryDate: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+				Registrar:  "Unknown",
+				Status:     "unknown",
+				Method:     "rdap",
+			},
+		},
+		{domain: "unregistered.com", wantErr: ErrDomainNotFound},
+		{domain: "limited.com", wantErr: ErrRateLimited},
+		{domain: "broken.com", wantErr: ErrLookupNetwork},
+		{domain: "noexpiry.com", wantErr: ErrMissingExpiry},
+		{domain: "baddate.com", wantErr: ErrUnparseableDate},
+		{domain: "invalid.com", wantErr: ErrInvalidResponse},
+		{domain: "example.org", wantErr: errProviderNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			info, err := provider.Lookup(context.Background(), tt.domain)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Lookup() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			if !reflect.DeepEqual(info, tt.want) {
+				t.Errorf("Lookup() =\n%+v\nwant\n%+v", info, tt.want)
+			}
+		})
+	}
+
+	// RDAP状态转换为EPP状态码
+	info, err := provider.Lookup(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	want := []string{"clientDeleteProhibited", "clientTransferProhibited", "ok"}
+	if codes := statusCodes(info.Statuses); !reflect.DeepEqual(codes, want) {
+		t.Errorf("statusCodes() = %v, want %v", codes, want)
+	}
+}
+
+func TestRDAPBootstrap(t *testing.T) {
+	server := newFakeRDAPServer(t, nil)
+	client := NewRDAPClient()
+	client.httpClient = server.Client()
+	client.bootstrapURL = server.URL + "/dns.json"
+
+	tests := []struct {
+		tld  string
+		want string
+	}{
+		{tld: "com", want: server.URL + "/"},
+		{tld: "NET.", want: server.URL + "/"},
+		{tld: "org", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := client.BaseURL(context.Background(), tt.tld)
+		if err != nil {
+			t.Fatalf("BaseURL(%q) error = %v", tt.tld, err)
+		}
+		if got != tt.want {
+			t.Errorf("BaseURL(%q) = %q, want %q", tt.tld, got, tt.want)
+		}
+	}
+}
+
+func TestRDAPBootstrapFailureBackoff(t *testing.T) {
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewRDAPClient()
+	client.bootstrapURL = server.URL + "/dns.json"
+	provider := &rdapProvider{client: client, config: &Config{Timeout: 5}}
+
+	for i := 0; i < 3; i++ {
+		if _, err := provider.Lookup(context.Background(), "example.com"); !errors.Is(err, errProviderNotApplicable) {
+			t.Fatalf("Lookup() error = %v, want errProviderNotApplicable", err)
+		}
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("引导文件下载次数 = %d, want 1（重试间隔内不再下载）", got)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// schedulerTick 调度器检查到期域名的间隔
+const schedulerTick = 30 * time.Second
+
+// ScheduleConfig 按剩余天数自适应的检查间隔配置
+type ScheduleConfig struct {
+	Adaptive        bool           `yaml:"adaptive"`         // 是否启用自适应间隔，关闭时所有域名使用check_interval
+	Tiers           []ScheduleTier `yaml:"tiers"`            // 按剩余天数分档的检查间隔
+	ExpiredInterval int            `yaml:"expired_interval"` // 已过期（续费宽限期内）域名的检查间隔（秒）
+}
+
+// ScheduleTier 检查间隔分档：剩余天数不小于MinDays时使用Interval
+type ScheduleTier struct {
+	MinDays  int `yaml:"min_days"` // 剩余天数下限（含）
+	Interval int `yaml:"interval"` // 检查间隔（秒）
+}
+
+// defaultScheduleTiers 启用自适应间隔但未配置分档时使用
+var defaultScheduleTiers = []ScheduleTier{
+	{MinDays: 90, Interval: 86400}, // 90天以上：每天
+	{MinDays: 30, Interval: 21600}, // 30-90天：每6小时
+	{MinDays: 0, Interval: 3600},   // 30天以内：每小时
+}
+
+// checkIntervalFor 根据域名上次检查结果计算注册信息的检查间隔，只参考注册过期时间，
+// TLS证书的过期时间不影响WHOIS/RDAP查询频率
+func (c *Config) checkIntervalFor(state DomainState, now time.Time) time.Duration {
+	var expiry time.Time
+	if state.Success && state.Info != nil {
+		expiry = state.Info.ExpiryDate
+	}
+	return c.intervalForExpiry(expiry, now)
+}
+
+// tlsCheckIntervalFor 计算TLS证书的检查间隔：配置了tls_check.interval时使用固定间隔，
+// 否则按最早过期的证书使用与注册信息相同的分档
+func (c *Config) tlsCheckIntervalFor(state DomainState, now time.Time) time.Duration {
+	if c.TLSCheck.Interval > 0 {
+		return time.Duration(c.TLSCheck.Interval) * time.Second
+	}
+
+	var expiry time.Time
+	for _, cert := range state.TLSCerts {
+		if expiry.IsZero() || cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+	}
+	return c.intervalForExpiry(expiry, now)
+}
+
+// intervalForExpiry 按剩余天数匹配检查间隔，未启用自适应间隔或没有可用的过期时间时使用全局间隔
+func (c *Config) intervalForExpiry(expiry, now time.Time) time.Duration {
+	base := time.Duration(c.CheckInterval) * time.Second
+	if !c.Schedule.Adaptive {
+		return base
+	}
+
+	// 没有可用的过期时间（如检查失败）时使用全局间隔
+	if expiry.IsZero() {
+		return base
+	}
+
+	days := expiry.Sub(now).Hours() / 24
+	if days < 0 {
+		return time.Duration(c.Schedule.ExpiredInterval) * time.Second
+	}
+
+	tiers := c.Schedule.Tiers
+	if len(tiers) == 0 {
+		tiers = defaultScheduleTiers
+	}
+
+	// 按剩余天数下限从高到低匹配
+	sorted := make([]ScheduleTier, len(tiers))
+	copy(sorted, tiers)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].MinDays > sorted[j].MinDays })
+
+	for _, tier := range sorted {
+		if days >= float64(tier.MinDays) && tier.Interval > 0 {
+			return time.Duration(tier.Interval) * time.Second
+		}
+	}
+	return base
+}
+
+// nextCheckTime 计算域名的下次检查时间（从未检查过的域名立即检查）
+func (c *Config) nextCheckTime(state DomainState, ok bool, now time.Time) time.Time {
+	if !ok || state.LastCheck.IsZero() {
+		return now
+	}
+	return state.LastCheck.Add(c.checkIntervalFor(state, now))
+}
+
+// nextTLSCheckTime 计算域名TLS证书的下次检查时间（从未检查过的域名立即检查）
+func (c *Config) nextTLSCheckTime(state DomainState, ok bool, now time.Time) time.Time {
+	if !ok || state.TLSLastCheck.IsZero() {
+		return now
+	}
+	return state.TLSLastCheck.Add(c.tlsCheckIntervalFor(state, now))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextCheckTime(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	lastCheck := now.Add(-10 * time.Minute)
+	adaptive := &Config{CheckInterval: 3600, Schedule: ScheduleConfig{Adaptive: true, ExpiredInterval: 900}}
+	customTiers := &Config{CheckInterval: 3600, Schedule: ScheduleConfig{Adaptive: true, ExpiredInterval: 900, Tiers: []ScheduleTier{
+		{MinDays: 30, Interval: 43200},
+		{MinDays: 7, Interval: 7200},
+		{MinDays: 0, Interval: 1800},
+	}}}
+	fixed := &Config{CheckInterval: 3600, Schedule: ScheduleConfig{ExpiredInterval: 900}}
+
+	checked := func(days int) DomainState {
+		return DomainState{
+			Info:      &DomainInfo{ExpiryDate: now.AddDate(0, 0, days)},
+			LastCheck: lastCheck,
+			Success:   true,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		config *Config
+		state  DomainState
+		ok     bool
+		want   time.Duration // 相对上次检查时间的间隔
+	}{
+		{name: "90天以上", config: adaptive, state: checked(365), ok: true, want: 24 * time.Hour},
+		{name: "正好90天", config: adaptive, state: checked(90), ok: true, want: 24 * time.Hour},
+		{name: "30-90天", config: adaptive, state: checked(60), ok: true, want: 6 * time.Hour},
+		{name: "30天以内", config: adaptive, state: checked(20), ok: true, want: time.Hour},
+		{name: "7天以内使用默认分档", config: adaptive, state: checked(3), ok: true, want: time.Hour},
+		{name: "7天以内使用自定义分档", config: customTiers, state: checked(3), ok: true, want: 30 * time.Minute},
+		{name: "7-30天使用自定义分档", config: customTiers, state: checked(20), ok: true, want: 2 * time.Hour},
+		{name: "已过期", config: adaptive, state: checked(-1), ok: true, want: 15 * time.Minute},
+		{
+			name:   "检查失败使用全局间隔",
+			config: adaptive,
+			state:  DomainState{Info: &DomainInfo{ExpiryDate: now.AddDate(1, 0, 0)}, LastCheck: lastCheck},
+			ok:     true,
+			want:   time.Hour,
+		},
+		{name: "未启用自适应间隔", config: fixed, state: checked(365), ok: true, want: time.Hour},
+		{name: "未启用自适应间隔且已过期", config: fixed, state: checked(-1), ok: true, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := lastCheck.Add(tt.want)
+			if got := tt.config.nextCheckTime(tt.state, tt.ok, now); !got.Equal(want) {
+				t.Errorf("nextCheckTime() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	// 从未检查过的域名立即检查
+	if got := adaptive.nextCheckTime(DomainState{}, false, now); !got.Equal(now) {
+		t.Errorf("nextCheckTime() = %v, want %v", got, now)
+	}
+	if got := adaptive.nextCheckTime(DomainState{Lifecycle: lifecycleAvailable}, true, now); !got.Equal(now) {
+		t.Errorf("nextCheckTime() = %v, want %v", got, now)
+	}
+}
+
+func TestTLSScheduleIndependentOfRegistration(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	config := &Config{CheckInterval: 3600, Schedule: ScheduleConfig{Adaptive: true, ExpiredInterval: 900}}
+
+	// 注册信息还有一年过期，证书（如Let's Encrypt）只剩20天
+	state := DomainState{
+		Info:         &DomainInfo{ExpiryDate: now.AddDate(1, 0, 0)},
+		LastCheck:    now,
+		Success:      true,
+		TLSCerts:     map[string]*TLSCertInfo{"example.com:443": {NotAfter: now.AddDate(0, 0, 20)}},
+		TLSLastCheck: now,
+	}
+
+	if got := config.nextCheckTime(state, true, now); !got.Equal(now.Add(24 * time.Hour)) {
+		t.Errorf("nextCheckTime() = %v, want daily tier unaffected by TLS certificate", got)
+	}
+	if got := config.nextTLSCheckTime(state, true, now); !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("nextTLSCheckTime() = %v, want hourly tier for certificate", got)
+	}
+
+	config.TLSCheck.Interval = 600
+	if got := config.nextTLSCheckTime(state, true, now); !got.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("nextTLSCheckTime() = %v, want fixed tls_check.interval", got)
+	}
+
+	state.TLSLastCheck = time.Time{}
+	if got := config.nextTLSCheckTime(state, true, now); !got.Equal(now) {
+		t.Errorf("nextTLSCheckTime() = %v, want immediate check when never checked", got)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// serverDrainTimeout 端口切换时等待旧监听上进行中请求完成的最长时间
+const serverDrainTimeout = 10 * time.Second
+
+// HTTPServer 可在运行时切换监听端口的HTTP服务
+type HTTPServer struct {
+	handler http.Handler
+	pinned  bool // 端口由命令行或环境变量指定时不随配置变化
+
+	mutex  sync.Mutex
+	server *http.Server
+	port   int
+	closed bool
+
+	listenPort     prometheus.Gauge
+	portMismatch   prometheus.Gauge
+	rebindFailures prometheus.Counter
+}
+
+// NewHTTPServer 创建HTTP服务，pinned为true时忽略配置中的端口变化
+func NewHTTPServer(handler http.Handler, pinned bool) *HTTPServer {
+	return &HTTPServer{
+		handler: handler,
+		pinned:  pinned,
+		listenPort: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "domain_exporter_http_listen_port",
+			Help: "HTTP服务当前监听的端口",
+		}),
+		portMismatch: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "domain_exporter_http_port_mismatch",
+			Help: "配置的端口是否未生效 (1=与实际监听端口不一致)",
+		}),
+		rebindFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "domain_exporter_http_rebind_failures_total",
+			Help: "按配置切换HTTP监听端口失败的次数",
+		}),
+	}
+}
+
+// Start 在指定端口启动监听
+func (s *HTTPServer) Start(port int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	server, err := s.listen(port)
+	if err != nil {
+		return err
+	}
+	s.server = server
+	s.port = port
+	s.listenPort.Set(float64(port))
+	return nil
+}
+
+// Rebind 切换到新端口：先启动新监听，成功后再优雅关闭旧监听；失败时保留旧监听
+func (s *HTTPServer) Rebind(port int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed || port == s.port {
+		s.portMismatch.Set(0)
+		return nil
+	}
+
+	if s.pinned {
+		s.portMismatch.Set(1)
+		slog.Warn("HTTP端口由命令行或环境变量指定，忽略配置中的端口变化", "listen_port", s.port, "config_port", port)
+		return nil
+	}
+
+	server, err := s.listen(port)
+	if err != nil {
+		s.portMismatch.Set(1)
+		s.rebindFailures.Inc()
+		return err
+	}
+
+	oldServer, oldPort := s.server, s.port
+	s.server = server
+	s.port = port
+	s.listenPort.Set(float64(port))
+	s.portMismatch.Set(0)
+	slog.Info("HTTP服务已切换端口", "old_port", oldPort, "new_port", port)
+
+	// 在后台等待旧监听上的请求处理完成
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), serverDrainTimeout)
+		defer cancel()
+		if err := oldServer.Shutdown(ctx); err != nil {
+			slog.Warn("关闭旧HTTP监听超时，强制关闭", "port", oldPort, "error", err)
+			oldServer.Close()
+		}
+	}()
+	return nil
+}
+
+// Close 关闭HTTP服务
+func (s *HTTPServer) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+// listen 绑定端口并在后台提供服务
+func (s *HTTPServer) listen(port int) (*http.Server, error) {
+	addr := ":" + strconv.Itoa(port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("监听端口 %d 失败: %w", port, err)
+	}
+
+	server := &http.Server{Addr: addr, Handler: s.handler}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP服务异常退出", "port", port, "error", err)
+		}
+	}()
+
+	slog.Info("启动HTTP服务", "port", port)
+	return server, nil
+}
+
+// Describe 实现prometheus.Collector接口
+func (s *HTTPServer) Describe(ch chan<- *prometheus.Desc) {
+	s.listenPort.Describe(ch)
+	s.portMismatch.Describe(ch)
+	s.rebindFailures.Describe(ch)
+}
+
+// Collect 实现prometheus.Collector接口
+func (s *HTTPServer) Collect(ch chan<- prometheus.Metric) {
+	s.listenPort.Collect(ch)
+	s.portMismatch.Collect(ch)
+	s.rebindFailures.Collect(ch)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DomainState 单个域名的最近检查结果
+type DomainState struct {
+	Info        *DomainInfo `json:"info,omitempty"`      // 最近一次成功获取的域名信息
+	LastCheck   time.Time   `json:"last_check"`          // 最近一次检查时间
+	LastSuccess time.Time   `json:"last_success"`        // 最近一次成功检查时间
+	Success     bool        `json:"success"`             // 最近一次检查是否成功
+	Lifecycle   string      `json:"lifecycle,omitempty"` // 最近确定的生命周期状态（查询返回域名不存在时为available）
+
+	TLSCerts     map[string]*TLSCertInfo `json:"tls_certs,omitempty"`     // 各TLS端点最近一次成功获取的证书信息
+	TLSLastCheck time.Time               `json:"tls_last_check,omitzero"` // 最近一次TLS证书检查时间，与注册信息分开调度
+}
+
+// stateSnapshot 状态文件格式
+type stateSnapshot struct {
+	SavedAt time.Time               `json:"saved_at"`
+	Domains map[string]*DomainState `json:"domains"`
+}
+
+// StateStore 域名检查结果存储，配置了路径时以JSON快照形式持久化，重启后可恢复
+type StateStore struct {
+	path    string
+	mutex   sync.RWMutex
+	domains map[string]*DomainState
+}
+
+// NewStateStore 创建状态存储（path为空时仅保存在内存中）
+func NewStateStore(path string) *StateStore {
+	return &StateStore{
+		path:    path,
+		domains: make(map[string]*DomainState),
+	}
+}
+
+// Load 从状态文件加载，文件不存在时不视为错误
+func (s *StateStore) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			slog.Info("状态文件不存在，将在首次检查后创建", "path", s.path)
+			return nil
+		}
+		return fmt.Errorf("读取状态文件失败: %w", err)
+	}
+
+	var snapshot stateSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("解析状态文件失败: %w", err)
+	}
+
+	s.mutex.Lock()
+	if snapshot.Domains != nil {
+		s.domains = snapshot.Domains
+	}
+	s.mutex.Unlock()
+
+	slog.Info("已加载状态文件", "path", s.path, "domain_count", len(snapshot.Domains), "saved_at", snapshot.SavedAt)
+	return nil
+}
+
+// Save 将当前状态写入状态文件（先写临时文件再重命名，避免写入中断导致文件损坏）
+func (s *StateStore) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.mutex.RLock()
+	data, err := json.MarshalIndent(stateSnapshot{SavedAt: time.Now(), Domains: s.domains}, "", "  ")
+	s.mutex.RUnlock()
+	if err != nil {
+		return fmt.Errorf("序列化状态失败: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("创建临时状态文件失败: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("写入状态文件失败: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("写入状态文件失败: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), s.path); err != nil {
+		return fmt.Errorf("替换状态文件失败: %w", err)
+	}
+
+	slog.Debug("状态文件已保存", "path", s.path)
+	return nil
+}
+
+// Get 获取域名状态的副本
+func (s *StateStore) Get(domain string) (DomainState, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	state, ok := s.domains[domain]
+	if !ok {
+		return DomainState{}, false
+	}
+	return *state, true
+}
+
+// RecordSuccess 记录一次成功检查
+func (s *StateStore) RecordSuccess(domain string, info *DomainInfo, at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, ok := s.domains[domain]
+	if !ok {
+		state = &DomainState{}
+		s.domains[domain] = state
+	}
+	state.Info = info
+	state.LastCheck = at
+	state.LastSuccess = at
+	state.Success = true
+	state.Lifecycle = info.State
+}
+
+// RecordFailure 记录一次失败检查，保留上一次成功获取的域名信息
+func (s *StateStore) RecordFailure(domain string, at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, ok := s.domains[domain]
+	if !ok {
+		state = &DomainState{}
+		s.domains[domain] = state
+	}
+	state.LastCheck = at
+	state.Success = false
+}
+
+// RecordLifecycle 记录检查失败时确定的生命周期状态（如查询返回域名不存在）
+func (s *StateStore) RecordLifecycle(domain, lifecycle string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, ok := s.domains[domain]
+	if !ok {
+		state = &DomainState{}
+		s.domains[domain] = state
+	}
+	state.Lifecycle = lifecycle
+}
+
+// RecordTLS 记录TLS证书检查时间及各TLS端点最近一次成功获取的证书信息
+func (s *StateStore) RecordTLS(domain string, certs map[string]*TLSCertInfo, now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, ok := s.domains[domain]
+	if !ok {
+		state = &DomainState{}
+		s.domains[domain] = state
+	}
+	state.TLSCerts = certs
+	state.TLSLastCheck = now
+}
+
+// Delete 删除域名状态
+func (s *StateStore) Delete(domain string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.domains, domain)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStateStoreSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	info := &DomainInfo{
+		Domain:     "example.com",
+		ExpiryDate: time.Date(2030, 8, 13, 4, 0, 0, 0, time.UTC),
+		Registrar:  "Example Registrar, Inc.",
+		Statuses:   []string{"clientTransferProhibited"},
+		Method:     "rdap",
+		State:      lifecycleRegistered,
+	}
+	certs := map[string]*TLSCertInfo{"example.com:443": {NotAfter: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)}}
+
+	store := NewStateStore(path)
+	store.RecordSuccess("example.com", info, now)
+	store.RecordTLS("example.com", certs, now)
+	store.RecordFailure("example.com", now.Add(time.Hour))
+	store.RecordFailure("missing.com", now)
+	store.RecordLifecycle("missing.com", lifecycleAvailable)
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded := NewStateStore(path)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for _, domain := range []string{"example.com", "missing.com"} {
+		want, _ := store.Get(domain)
+		got, ok := loaded.Get(domain)
+		if !ok {
+			t.Fatalf("加载后缺少 %s 的状态", domain)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s 的状态 =\n%+v\nwant\n%+v", domain, got, want)
+		}
+	}
+
+	// 临时文件在重命名后不应残留
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("状态目录中有 %d 个文件, want 1", len(entries))
+	}
+}
+
+func TestStateStoreLoadTruncatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := NewStateStore(path)
+	store.RecordSuccess("example.com", &DomainInfo{Domain: "example.com", ExpiryDate: time.Now().AddDate(1, 0, 0)}, time.Now())
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if err := os.WriteFile(path, data[:len(data)/2], 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	loaded := NewStateStore(path)
+	if err := loaded.Load(); err == nil {
+		t.Error("Load() 截断的状态文件应返回错误")
+	}
+	if _, ok := loaded.Get("example.com"); ok {
+		t.Error("加载截断的状态文件后应从空状态开始")
+	}
+
+	// 加载失败后仍可正常记录和保存
+	loaded.RecordFailure("example.com", time.Now())
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := NewStateStore(path).Load(); err != nil {
+		t.Errorf("重新保存后 Load() error = %v", err)
+	}
+}
+
+func TestStateStoreLoadMissingFile(t *testing.T) {
+	store := NewStateStore(filepath.Join(t.TempDir(), "state.json"))
+	if err := store.Load(); err != nil {
+		t.Errorf("Load() 状态文件不存在时 error = %v, want nil", err)
+	}
+}
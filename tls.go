@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+)
+
+// TLSCheckConfig TLS证书检查全局配置
+type TLSCheckConfig struct {
+	Enabled  bool `yaml:"enabled"`  // 为所有域名启用TLS证书检查
+	Port     int  `yaml:"port"`     // 未指定端口时使用的默认端口
+	Interval int  `yaml:"interval"` // 证书检查间隔（秒），为0时按证书剩余天数使用schedule分档
+}
+
+// DomainTLSConfig 域名级TLS证书检查配置
+type DomainTLSConfig struct {
+	Enabled    *bool    `yaml:"enabled" json:"enabled,omitempty"`         // 覆盖全局开关
+	Endpoints  []string `yaml:"endpoints" json:"endpoints,omitempty"`     // 检查的host:port列表，默认为域名本身
+	ServerName string   `yaml:"server_name" json:"server_name,omitempty"` // SNI，默认为域名
+}
+
+// TLSCertInfo TLS证书检查结果
+type TLSCertInfo struct {
+	Endpoint    string    `json:"endpoint"`
+	NotAfter    time.Time `json:"not_after"`
+	Issuer      string    `json:"issuer"`
+	Subject     string    `json:"subject"`
+	ChainValid  bool      `json:"chain_valid"`
+	VerifyError string    `json:"verify_error,omitempty"`
+}
+
+// tlsEndpoints 获取域名需要检查的TLS端点和SNI，未启用时返回空
+func (c *Config) tlsEndpoints(entry DomainEntry) ([]string, string) {
+	enabled := c.TLSCheck.Enabled
+	serverName := entry.Name
+	var endpoints []string
+
+	if entry.TLS != nil {
+		if entry.TLS.Enabled != nil {
+			enabled = *entry.TLS.Enabled
+		} else if len(entry.TLS.Endpoints) > 0 {
+			enabled = true
+		}
+		if entry.TLS.ServerName != "" {
+			serverName = entry.TLS.ServerName
+		}
+		endpoints = entry.TLS.Endpoints
+	}
+
+	if !enabled {
+		return nil, ""
+	}
+	if len(endpoints) == 0 {
+		endpoints = []string{entry.Name}
+	}
+
+	port := strconv.Itoa(c.TLSCheck.Port)
+	result := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			endpoint = net.JoinHostPort(endpoint, port)
+		}
+		result = append(result, endpoint)
+	}
+	return result, serverName
+}
+
+// CheckTLSCertificate 连接端点并获取叶子证书信息，同时校验证书链
+func CheckTLSCertificate(ctx context.Context, endpoint, serverName string, timeout time.Duration) (*TLSCertInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	slog.DebugContext(ctx, "开始TLS证书检查", "endpoint", endpoint, "server_name", serverName)
+
+	// 先跳过校验以便在证书无效时仍能获取证书信息，随后手动校验证书链
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName:         serverName,
+			InsecureSkipVerify: true,
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("TLS连接失败: %w", err)
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("TLS端点未返回证书: %s", endpoint)
+	}
+
+	leaf := certs[0]
+	info := &TLSCertInfo{
+		Endpoint: endpoint,
+		NotAfter: leaf.NotAfter,
+		Issuer:   leaf.Issuer.CommonName,
+		Subject:  leaf.Subject.CommonName,
+	}
+	if info.Issuer == "" {
+		info.Issuer = leaf.Issuer.String()
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Intermediates: intermediates,
+	})
+	info.ChainValid = err == nil
+	if err != nil {
+		info.VerifyError = err.Error()
+		slog.DebugContext(ctx, "TLS证书链校验失败", "endpoint", endpoint, "error", err)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckTLSCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	endpoint := strings.TrimPrefix(server.URL, "https://")
+	info, err := CheckTLSCertificate(context.Background(), endpoint, "example.com", 5*time.Second)
+	if err != nil {
+		t.Fatalf("CheckTLSCertificate() error = %v", err)
+	}
+
+	cert := server.Certificate()
+	if info.Endpoint != endpoint {
+		t.Errorf("Endpoint = %q, want %q", info.Endpoint, endpoint)
+	}
+	if !info.NotAfter.Equal(cert.NotAfter) {
+		t.Errorf("NotAfter = %v, want %v", info.NotAfter, cert.NotAfter)
+	}
+	// 测试证书的签发者没有CN，使用完整的DN
+	if want := cert.Issuer.String(); info.Issuer != want {
+		t.Errorf("Issuer = %q, want %q", info.Issuer, want)
+	}
+	// 自签名的测试证书不受系统信任
+	if info.ChainValid {
+		t.Error("ChainValid = true, want false for self-signed certificate")
+	}
+	if info.VerifyError == "" {
+		t.Error("VerifyError is empty, want verification error")
+	}
+}
+
+func TestCheckTLSCertificateConnectError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+
+	if _, err := CheckTLSCertificate(context.Background(), endpoint, "example.com", time.Second); err == nil {
+		t.Fatal("CheckTLSCertificate() error = nil, want connection error")
+	}
+}
+
+func TestTLSEndpoints(t *testing.T) {
+	enabled, disabled := true, false
+
+	tests := []struct {
+		name           string
+		global         TLSCheckConfig
+		tls            *DomainTLSConfig
+		wantEndpoints  []string
+		wantServerName string
+	}{
+		{
+			name:   "全局未启用",
+			global: TLSCheckConfig{Enabled: false, Port: 443},
+		},
+		{
+			name:           "全局启用时使用域名和默认端口",
+			global:         TLSCheckConfig{Enabled: true, Port: 443},
+			wantEndpoints:  []string{"example.com:443"},
+			wantServerName: "example.com",
+		},
+		{
+			name:           "使用配置的默认端口",
+			global:         TLSCheckConfig{Enabled: true, Port: 8443},
+			wantEndpoints:  []string{"example.com:8443"},
+			wantServerName: "example.com",
+		},
+		{
+			name:   "域名级enabled覆盖全局启用",
+			global: TLSCheckConfig{Enabled: true, Port: 443},
+			tls:    &DomainTLSConfig{Enabled: &disabled},
+		},
+		{
+			name:           "域名级enabled覆盖全局未启用",
+			global:         TLSCheckConfig{Enabled: false, Port: 443},
+			tls:            &DomainTLSConfig{Enabled: &enabled},
+			wantEndpoints:  []string{"example.com:443"},
+			wantServerName: "example.com",
+		},
+		{
+			name:           "配置endpoints时隐式启用，未指定端口的使用默认端口",
+			global:         TLSCheckConfig{Enabled: false, Port: 443},
+			tls:            &DomainTLSConfig{Endpoints: []string{"www.example.com", "10.0.0.1:8443"}},
+			wantEndpoints:  []string{"www.example.com:443", "10.0.0.1:8443"},
+			wantServerName: "example.com",
+		},
+		{
+			name:   "enabled为false时忽略endpoints",
+			global: TLSCheckConfig{Enabled: true, Port: 443},
+			tls:    &DomainTLSConfig{Enabled: &disabled, Endpoints: []string{"www.example.com"}},
+		},
+		{
+			name:           "自定义SNI",
+			global:         TLSCheckConfig{Enabled: true, Port: 443},
+			tls:            &DomainTLSConfig{Endpoints: []string{"lb.internal:443"}, ServerName: "www.example.com"},
+			wantEndpoints:  []string{"lb.internal:443"},
+			wantServerName: "www.example.com",
+		},
+		{
+			name:           "IPv6地址",
+			global:         TLSCheckConfig{Enabled: true, Port: 443},
+			tls:            &DomainTLSConfig{Endpoints: []string{"::1", "[::1]:8443"}},
+			wantEndpoints:  []string{"[::1]:443", "[::1]:8443"},
+			wantServerName: "example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{TLSCheck: tt.global}
+			endpoints, serverName := config.tlsEndpoints(DomainEntry{Name: "example.com", TLS: tt.tls})
+			if len(endpoints) != 0 || len(tt.wantEndpoints) != 0 {
+				if !reflect.DeepEqual(endpoints, tt.wantEndpoints) {
+					t.Errorf("endpoints = %v, want %v", endpoints, tt.wantEndpoints)
+				}
+			}
+			if serverName != tt.wantServerName {
+				t.Errorf("serverName = %q, want %q", serverName, tt.wantServerName)
+			}
+		})
+	}
+}
@@ -0,0 +1,514 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 配置项取值范围（0表示使用默认值）
+const (
+	minCheckInterval       = 60            // 检查间隔下限（秒）
+	maxCheckInterval       = 7 * 24 * 3600 // 检查间隔上限（秒）
+	maxTimeout             = 300           // 查询超时上限（秒）
+	maxConcurrency         = 100           // 并发worker数量上限
+	minDomainSourceRefresh = 10            // 域名来源刷新间隔下限（秒）
+)
+
+// ConfigError 配置校验错误，Line为配置文件中的行号（无法定位时为0）
+type ConfigError struct {
+	Line    int
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+// ConfigErrors 配置校验发现的全部错误
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ParseConfig 严格解析配置文件内容（拒绝未知配置项和重复的键）并校验取值，
+// 返回的错误为ConfigErrors，包含所有能定位到的行号
+func ParseConfig(data []byte) (*Config, error) {
+	var config Config
+	var errs ConfigErrors
+
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			// 语法错误时无法继续校验
+			return nil, ConfigErrors{yamlConfigError(err.Error())}
+		}
+		// 类型错误时yaml仍会填充其余字段，继续校验以便一次报告所有问题
+		for _, message := range typeErr.Errors {
+			errs = append(errs, yamlConfigError(message))
+		}
+	}
+
+	errs = append(errs, validateConfig(&config, data)...)
+	if len(errs) > 0 {
+		// 按行号排序，无法定位行号的错误放在最后
+		sort.SliceStable(errs, func(i, j int) bool {
+			if errs[i].Line == 0 || errs[j].Line == 0 {
+				return errs[j].Line == 0 && errs[i].Line != 0
+			}
+			return errs[i].Line < errs[j].Line
+		})
+		return nil, errs
+	}
+	return &config, nil
+}
+
+var (
+	yamlLinePattern      = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	yamlUnknownPattern   = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+	yamlDuplicatePattern = regexp.MustCompile(`^(?:key|field) "?(.*?)"? already set in `)
+)
+
+// yamlConfigError 将yaml库的错误信息转换为带行号的ConfigError
+func yamlConfigError(message string) ConfigError {
+	match := yamlLinePattern.FindStringSubmatch(message)
+	if match == nil {
+		return ConfigError{Message: strings.TrimPrefix(message, "yaml: ")}
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	detail := match[2]
+	if field := yamlUnknownPattern.FindStringSubmatch(detail); field != nil {
+		detail = fmt.Sprintf("未知的配置项: %s", field[1])
+	} else if key := yamlDuplicatePattern.FindStringSubmatch(detail); key != nil {
+		detail = fmt.Sprintf("重复的配置项: %s", key[1])
+	}
+	return ConfigError{Line: line, Message: detail}
+}
+
+// configValidator 校验配置取值，并通过在原始内容中查找键或值为错误定位行号
+type configValidator struct {
+	lines  []string
+	errors ConfigErrors
+}
+
+// rawDomainLists 非严格解析的域名列表，保留解析时因错误被丢弃的条目，用于计算条目在配置文件中的下标
+type rawDomainLists struct {
+	Domains       []interface{} `yaml:"domains"`
+	DomainSources []struct {
+		Domains []interface{} `yaml:"domains"`
+	} `yaml:"domain_sources"`
+}
+
+// validateConfig 校验配置取值，data为原始配置内容，用于定位行号
+func validateConfig(config *Config, data []byte) ConfigErrors {
+	v := &configValidator{lines: strings.Split(string(data), "\n")}
+
+	var raw rawDomainLists
+	_ = yaml.Unmarshal(data, &raw)
+
+	v.checkRange(config.CheckInterval, minCheckInterval, maxCheckInterval, "check_interval")
+	v.checkRange(config.Timeout, 1, maxTimeout, "timeout")
+	v.checkRange(config.Port, 1, 65535, "port")
+	v.checkRange(config.Concurrency, 1, maxConcurrency, "concurrency")
+	v.checkOneOf(config.LogLevel, []string{"debug", "info", "warn", "warning", "error"}, "log_level", v.top("log_level"))
+	v.checkOneOf(config.ConfigSource, []string{"nacos", "consul", "etcd", "apollo", "file"}, "config_source", v.top("config_source"))
+	v.checkOneOf(config.NacosAPIVersion, []string{"auto", "v1", "v2"}, "nacos_api_version", v.top("nacos_api_version"))
+
+	if config.NacosUrl != "" {
+		if parsed, err := url.Parse(config.NacosUrl); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			v.addf(v.top("nacos_url"), "nacos_url必须是http或https地址: %s", config.NacosUrl)
+		}
+	}
+
+	v.validateDomains(config.Domains, raw.Domains, v.top("domains"), "domains")
+	v.validateProviders(config.Providers)
+
+	manualLine := v.top("manual_expiry")
+	for domain, date := range config.ManualExpiry {
+		line := v.find(manualLine, "", domain)
+		if err := validateDomainName(domain); err != nil {
+			v.addf(line, "manual_expiry: %v", err)
+		}
+		if _, err := parseFlexibleDate(date); err != nil {
+			v.addf(line, "manual_expiry中 %s 的日期无法解析: %s", domain, date)
+		}
+	}
+
+	rateLine := v.top("rate_limit")
+	v.checkNonNegative(config.RateLimit.QueriesPerMinute, rateLine, "queries_per_minute", "rate_limit.queries_per_minute")
+	v.checkNonNegative(config.RateLimit.Burst, rateLine, "burst", "rate_limit.burst")
+	for server, rule := range config.RateLimit.Servers {
+		line := v.find(rateLine, server, "")
+		v.checkNonNegative(rule.QueriesPerMinute, line, "", "rate_limit.servers."+server+".queries_per_minute")
+		v.checkNonNegative(rule.Burst, line, "", "rate_limit.servers."+server+".burst")
+	}
+
+	scheduleLine := v.top("schedule")
+	if value := config.Schedule.ExpiredInterval; value != 0 && (value < minCheckInterval || value > maxCheckInterval) {
+		v.addf(v.find(scheduleLine, "expired_interval", ""), "schedule.expired_interval必须在%d到%d之间: %d", minCheckInterval, maxCheckInterval, value)
+	}
+	tierLine := v.find(scheduleLine, "tiers", "")
+	for i, tier := range config.Schedule.Tiers {
+		tierLine = v.find(tierLine, "interval", "")
+		if tier.MinDays < 0 {
+			v.addf(tierLine, "schedule.tiers[%d].min_days不能为负数: %d", i, tier.MinDays)
+		}
+		if tier.Interval < minCheckInterval || tier.Interval > maxCheckInterval {
+			v.addf(tierLine, "schedule.tiers[%d].interval必须在%d到%d之间: %d", i, minCheckInterval, maxCheckInterval, tier.Interval)
+		}
+	}
+
+	tlsLine := v.top("tls_check")
+	if port := config.TLSCheck.Port; port != 0 && (port < 1 || port > 65535) {
+		v.addf(v.find(tlsLine, "port", ""), "tls_check.port必须在1到65535之间: %d", port)
+	}
+	if value := config.TLSCheck.Interval; value != 0 && (value < minCheckInterval || value > maxCheckInterval) {
+		v.addf(v.find(tlsLine, "interval", ""), "tls_check.interval必须在%d到%d之间: %d", minCheckInterval, maxCheckInterval, value)
+	}
+
+	v.validateDomainSources(config.DomainSources, raw)
+
+	return v.errors
+}
+
+// validateDomains 校验域名列表：域名格式、重复项和域名级覆盖项，raw为配置文件中的原始列表，用于报告条目的下标
+func (v *configValidator) validateDomains(domains []DomainEntry, raw []interface{}, startLine int, field string) {
+	indices := domainEntryIndices(domains, raw)
+	seen := make(map[string]int)
+	next := 0
+	for n, entry := range domains {
+		i := indices[n]
+
+		// 跳过解析时被丢弃的条目，避免同名域名定位到被丢弃条目所在的行
+		for ; next < i; next++ {
+			if name := rawDomainName(raw[next]); name != "" {
+				startLine = v.find(startLine, "", name)
+			}
+		}
+		next = i + 1
+
+		// 依次向后查找，重复的域名定位到各自出现的位置
+		line := v.find(startLine, "", entry.Name)
+		if line > 0 {
+			startLine = line
+		}
+
+		if err := validateDomainName(entry.Name); err != nil {
+			v.addf(line, "%s[%d]: %v", field, i, err)
+		}
+		key := strings.ToLower(entry.Name)
+		if first, ok := seen[key]; ok {
+			v.addf(line, "%s[%d]: 域名重复: %s（与%s[%d]相同）", field, i, entry.Name, field, first)
+		} else {
+			seen[key] = i
+		}
+
+		if entry.Timeout < 0 || entry.Timeout > maxTimeout {
+			v.addf(line, "%s[%d].timeout必须在1到%d之间: %d", field, i, maxTimeout, entry.Timeout)
+		}
+		for _, name := range entry.Providers {
+			if !isProviderRegistered(name) {
+				v.addf(line, "%s[%d].providers: 未知的提供者: %s", field, i, name)
+			}
+		}
+		if entry.TLS != nil {
+			for _, endpoint := range entry.TLS.Endpoints {
+				if _, port, err := net.SplitHostPort(endpoint); err == nil {
+					if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+						v.addf(line, "%s[%d].tls.endpoints: 端口无效: %s", field, i, endpoint)
+					}
+				}
+			}
+		}
+	}
+}
+
+// domainEntryIndices 计算解析结果中各域名条目在原始列表中的下标。
+// 含未知配置项等错误的条目会被yaml库从解析结果中丢弃，直接使用解析结果的下标会与配置文件错位
+func domainEntryIndices(domains []DomainEntry, raw []interface{}) []int {
+	indices := make([]int, 0, len(domains))
+	for i, item := range raw {
+		data, err := yaml.Marshal(item)
+		if err != nil {
+			continue
+		}
+		var entry DomainEntry
+		if yaml.UnmarshalStrict(data, &entry) == nil {
+			indices = append(indices, i)
+		}
+	}
+
+	// 与解析结果无法对应时使用解析结果的下标
+	if len(indices) != len(domains) {
+		indices = indices[:0]
+		for i := range domains {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// rawDomainName 获取原始域名条目的名称（支持字符串和对象写法）
+func rawDomainName(item interface{}) string {
+	switch value := item.(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case map[interface{}]interface{}:
+		if name, ok := value["name"].(string); ok {
+			return strings.TrimSpace(name)
+		}
+	}
+	return ""
+}
+
+// validateProviders 校验提供者链中的名称是否已注册
+func (v *configValidator) validateProviders(providers ProviderConfig) {
+	providersLine := v.top("providers")
+	for _, name := range providers.Default {
+		if !isProviderRegistered(name) {
+			v.addf(v.find(providersLine, "default", ""), "providers.default: 未知的提供者: %s", name)
+		}
+	}
+	for tld, chain := range providers.TLDs {
+		for _, name := range chain {
+			if !isProviderRegistered(name) {
+				v.addf(v.find(providersLine, tld, ""), "providers.tlds.%s: 未知的提供者: %s", tld, name)
+			}
+		}
+	}
+}
+
+// validateDomainSources 校验域名来源：类型、必填项、名称重复和刷新间隔
+func (v *configValidator) validateDomainSources(sources []DomainSourceConfig, raw rawDomainLists) {
+	line := v.top("domain_sources")
+	names := make(map[string]bool)
+	for i, source := range normalizeDomainSources(sources) {
+		if next := v.find(line, "type", ""); next > 0 {
+			line = next
+		}
+		field := fmt.Sprintf("domain_sources[%d]", i)
+
+		if names[source.Name] {
+			v.addf(line, "%s: 来源名称重复: %s", field, source.Name)
+		}
+		names[source.Name] = true
+
+		switch source.Type {
+		case "static":
+			var rawDomains []interface{}
+			if len(raw.DomainSources) == len(sources) {
+				rawDomains = raw.DomainSources[i].Domains
+			}
+			v.validateDomains(source.Domains, rawDomains, line, field+".domains")
+		case "file":
+			if len(source.Paths) == 0 {
+				v.addf(line, "%s: file类型的域名来源缺少paths", field)
+			}
+		case "http":
+			if parsed, err := url.Parse(source.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				v.addf(line, "%s: http类型的域名来源url必须是http或https地址: %s", field, source.URL)
+			}
+		case "nacos":
+			if source.DataId == "" {
+				v.addf(line, "%s: nacos类型的域名来源缺少data_id", field)
+			}
+		default:
+			v.addf(line, "%s: 未知的域名来源类型: %s（支持static、file、http、nacos）", field, source.Type)
+		}
+
+		v.checkOneOf(source.Format, []string{"yaml", "text"}, field+".format", line)
+		if interval := sources[i].RefreshInterval; interval != 0 && interval < minDomainSourceRefresh {
+			v.addf(line, "%s.refresh_interval不能小于%d: %d", field, minDomainSourceRefresh, interval)
+		}
+	}
+}
+
+// checkRange 校验顶层整数配置项的取值范围，0表示使用默认值
+func (v *configValidator) checkRange(value, min, max int, key string) {
+	if value != 0 && (value < min || value > max) {
+		v.addf(v.top(key), "%s必须在%d到%d之间: %d", key, min, max, value)
+	}
+}
+
+// checkNonNegative 校验整数配置项不为负数
+func (v *configValidator) checkNonNegative(value, fromLine int, key, field string) {
+	if value < 0 {
+		line := fromLine
+		if key != "" {
+			line = v.find(fromLine, key, "")
+		}
+		v.addf(line, "%s不能为负数: %d", field, value)
+	}
+}
+
+// checkOneOf 校验字符串配置项的取值，空字符串表示使用默认值
+func (v *configValidator) checkOneOf(value string, allowed []string, field string, line int) {
+	if value == "" {
+		return
+	}
+	for _, option := range allowed {
+		if strings.EqualFold(value, option) {
+			return
+		}
+	}
+	v.addf(line, "%s的取值无效: %s（支持%s）", field, value, strings.Join(allowed, "、"))
+}
+
+// top 查找顶层配置项（无缩进）所在的行号，找不到时返回0
+func (v *configValidator) top(key string) int {
+	for i, line := range v.lines {
+		if strings.HasPrefix(line, key+":") {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (v *configValidator) addf(line int, format string, args ...interface{}) {
+	v.errors = append(v.errors, ConfigError{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// find 从第from行之后查找以key为键且包含value的行（key或value为空时不作限制），
+// 返回行号（从1开始），找不到时返回from
+func (v *configValidator) find(from int, key, value string) int {
+	for i := from; i < len(v.lines); i++ {
+		line := v.lines[i]
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+
+		if key != "" && !containsKey(line, key) && !containsKey(line, strconv.Quote(key)) {
+			continue
+		}
+		if value != "" && !containsToken(line, value) {
+			continue
+		}
+		return i + 1
+	}
+	return from
+}
+
+// containsKey 判断line中是否包含键key（支持块写法和 {a: 1, b: 2} 流式写法）
+func containsKey(line, key string) bool {
+	for start := 0; ; {
+		index := strings.Index(line[start:], key+":")
+		if index < 0 {
+			return false
+		}
+		index += start
+		if index == 0 || strings.ContainsRune(" \t-{,", rune(line[index-1])) {
+			return true
+		}
+		start = index + 1
+	}
+}
+
+// containsToken 判断line中是否包含完整的value（前后不是域名字符），避免example.com匹配到pay.example.com
+func containsToken(line, value string) bool {
+	isNameChar := func(c byte) bool {
+		return c == '.' || c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+	for start := 0; ; {
+		index := strings.Index(line[start:], value)
+		if index < 0 {
+			return false
+		}
+		index += start
+		end := index + len(value)
+		if (index == 0 || !isNameChar(line[index-1])) && (end == len(line) || !isNameChar(line[end])) {
+			return true
+		}
+		start = index + 1
+	}
+}
+
+// validateDomainName 校验域名格式：至少两级，每级1-63个字符，由字母、数字、连字符（不在首尾）或非ASCII字符（国际化域名）组成
+func validateDomainName(name string) error {
+	if name == "" {
+		return fmt.Errorf("域名为空")
+	}
+	if len(name) > 253 {
+		return fmt.Errorf("域名超过253个字符: %s", name)
+	}
+
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("域名格式无效（至少需要两级）: %s", name)
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 {
+			return fmt.Errorf("域名格式无效（每级需为1-63个字符）: %s", name)
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("域名格式无效（连字符不能在首尾）: %s", name)
+		}
+		for _, r := range label {
+			if r < 0x80 && r != '-' && !(r >= '0' && r <= '9') && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') {
+				return fmt.Errorf("域名包含无效字符 %q: %s", r, name)
+			}
+		}
+	}
+	return nil
+}
+
+// runValidate 执行validate子命令：校验配置文件并输出带行号的错误，配置无效时返回非零退出码
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	filename := flags.String("config", "", "要校验的配置文件路径")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "用法: %s validate --config config.yml\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *filename == "" {
+		flags.Usage()
+		return 2
+	}
+
+	data, err := os.ReadFile(*filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取配置文件失败: %v\n", err)
+		return 1
+	}
+
+	config, err := ParseConfig(data)
+	if err != nil {
+		var configErrs ConfigErrors
+		if !errors.As(err, &configErrs) {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *filename, err)
+			return 1
+		}
+		for _, configErr := range configErrs {
+			if configErr.Line > 0 {
+				fmt.Fprintf(os.Stderr, "%s:%d: %s\n", *filename, configErr.Line, configErr.Message)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", *filename, configErr.Message)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "配置无效: 共%d个错误\n", len(configErrs))
+		return 1
+	}
+
+	fmt.Printf("%s: 配置有效（%d个域名，%d个域名来源）\n", *filename, len(config.Domains), len(config.DomainSources))
+	return 0
+}
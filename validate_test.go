@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseConfigDomainIndices(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want ConfigErrors
+	}{
+		{
+			name: "前面的条目因未知配置项被丢弃",
+			data: `check_interval: 3600
+domains:
+  - example.com
+  - name: example.net
+    bogus: 1
+  - bad_domain
+`,
+			want: ConfigErrors{
+				{Line: 5, Message: "未知的配置项: bogus"},
+				{Line: 6, Message: "domains[2]: 域名格式无效（至少需要两级）: bad_domain"},
+			},
+		},
+		{
+			name: "被丢弃的条目与后面的条目同名",
+			data: `domains:
+  - name: example.com
+    bogus: 1
+  - example.org
+  - name: example.com
+    timeout: 999
+`,
+			want: ConfigErrors{
+				{Line: 3, Message: "未知的配置项: bogus"},
+				{Line: 5, Message: "domains[2].timeout必须在1到300之间: 999"},
+			},
+		},
+		{
+			name: "重复域名引用原始下标",
+			data: `domains:
+  - name: example.com
+    bogus: 1
+  - example.org
+  - EXAMPLE.org
+`,
+			want: ConfigErrors{
+				{Line: 3, Message: "未知的配置项: bogus"},
+				{Line: 5, Message: "domains[2]: 域名重复: EXAMPLE.org（与domains[1]相同）"},
+			},
+		},
+		{
+			name: "静态域名来源",
+			data: `domain_sources:
+  - name: legacy
+    type: static
+    domains:
+      - name: a.example.com
+        bogus: 1
+      - bad_domain
+`,
+			want: ConfigErrors{
+				{Line: 6, Message: "未知的配置项: bogus"},
+				{Line: 7, Message: "domain_sources[0].domains[1]: 域名格式无效（至少需要两级）: bad_domain"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.data))
+			var errs ConfigErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("ParseConfig() error = %v, want ConfigErrors", err)
+			}
+			if !reflect.DeepEqual(errs, tt.want) {
+				t.Errorf("ParseConfig() errors =\n%v\nwant\n%v", errs, tt.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/likexian/whois"
@@ -14,57 +17,182 @@ import (
 
 // DomainInfo 域名信息结构
 type DomainInfo struct {
-	Domain     string
-	ExpiryDate time.Time
-	Registrar  string
-	Status     string
-	Method     string // 检测方法: whois
+	Domain          string    `json:"domain"`
+	ExpiryDate      time.Time `json:"expiry_date"`
+	CreatedDate     time.Time `json:"created_date,omitzero"` // 注册时间，未返回时为零值
+	UpdatedDate     time.Time `json:"updated_date,omitzero"` // 最后更新时间，未返回时为零值
+	Registrar       string    `json:"registrar"`
+	RegistrarIANAID string    `json:"registrar_iana_id,omitempty"`
+	WhoisServer     string    `json:"whois_server,omitempty"` // 注册商WHOIS服务器，未返回时为查询使用的服务器
+	Status          string    `json:"status"`
+	Statuses        []string  `json:"statuses,omitempty"` // 完整的EPP状态码列表
+	State           string    `json:"state,omitempty"`    // 生命周期状态，由EPP状态码和过期时间推断
+	Method          string    `json:"method"`             // 检测方法: rdap, whois, manual 或自定义提供者名称
 }
 
-// GetDomainInfo 获取域名信息
-func GetDomainInfo(domain string, timeout time.Duration) (*DomainInfo, error) {
-	slog.Debug("开始标准WHOIS查询", "domain", domain, "timeout", timeout)
-	
-	// 创建带超时的context
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+// ianaWhoisServer IANA的WHOIS服务器，用于查询TLD对应的WHOIS服务器
+const ianaWhoisServer = "whois.iana.org"
+
+// whoisServerCache TLD -> WHOIS服务器缓存，避免每个域名都查询IANA
+var whoisServerCache = struct {
+	sync.RWMutex
+	servers map[string]string
+}{servers: make(map[string]string)}
+
+// whoisServerPattern 匹配IANA响应中的WHOIS服务器字段（值可能为空，不能跨行匹配）
+var whoisServerPattern = regexp.MustCompile(`(?im)^[ \t]*(?:whois|refer):[ \t]*(\S*)`)
+
+// GetDomainInfo 通过WHOIS获取域名信息（按WHOIS服务器限速，超时从开始查询时计算）
+func GetDomainInfo(ctx context.Context, domain string, config *Config) (*DomainInfo, error) {
+	timeout := time.Duration(config.Timeout) * time.Second
+	tld := domainTLD(domain)
+
+	server, err := resolveWhoisServer(ctx, tld, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 未配置WHOIS服务器时由whois库经IANA查询，按IANA服务器限速
+	limitServer := server
+	if limitServer == "" {
+		limitServer = ianaWhoisServer
+	}
+	if err := defaultRateLimiter.Wait(ctx, limitServer, tld, config.RateLimit); err != nil {
+		return nil, fmt.Errorf("等待查询配额失败: %w", err)
+	}
+
+	slog.DebugContext(ctx, "开始标准WHOIS查询", "domain", domain, "server", server, "timeout", timeout)
+
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// 使用channel来处理超时
-	type result struct {
-		data string
-		err  error
+	data, err := whoisQuery(queryCtx, domain, server)
+	if err != nil {
+		return nil, err
 	}
 
-	resultChan := make(chan result, 1)
-	
-	// 在goroutine中执行whois查询
-	go func() {
-		slog.Debug("执行WHOIS查询", "domain", domain)
-		data, err := whois.Whois(domain)
-		if err != nil {
-			slog.Debug("WHOIS查询失败", "domain", domain, "error", err)
-		} else {
-			slog.Debug("WHOIS查询成功", "domain", domain, "data_length", len(data))
+	info, err := parseDomainInfo(ctx, domain, data)
+	if err != nil {
+		return nil, err
+	}
+	if info.WhoisServer == "" {
+		info.WhoisServer = server
+	}
+	return info, nil
+}
+
+// resolveWhoisServer 获取TLD对应的WHOIS服务器（通过IANA查询并缓存），
+// IANA未配置WHOIS服务器时返回空字符串，由whois库使用默认方式查询
+func resolveWhoisServer(ctx context.Context, tld string, config *Config) (string, error) {
+	whoisServerCache.RLock()
+	server, ok := whoisServerCache.servers[tld]
+	whoisServerCache.RUnlock()
+	if ok {
+		return server, nil
+	}
+
+	if err := defaultRateLimiter.Wait(ctx, ianaWhoisServer, "", config.RateLimit); err != nil {
+		return "", fmt.Errorf("等待查询配额失败: %w", err)
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, time.Duration(config.Timeout)*time.Second)
+	defer cancel()
+
+	data, err := whoisQuery(queryCtx, tld, ianaWhoisServer)
+	if err != nil {
+		return "", fmt.Errorf("查询TLD的WHOIS服务器失败: %w", err)
+	}
+
+	server = parseIANAWhoisServer(data)
+
+	whoisServerCache.Lock()
+	whoisServerCache.servers[tld] = server
+	whoisServerCache.Unlock()
+
+	if server == "" {
+		slog.DebugContext(ctx, "IANA未配置TLD的WHOIS服务器，使用默认查询", "tld", tld)
+	} else {
+		slog.DebugContext(ctx, "已解析TLD的WHOIS服务器", "tld", tld, "server", server)
+	}
+	return server, nil
+}
+
+// parseIANAWhoisServer 从IANA响应中提取第一个非空的WHOIS服务器，未配置时返回空字符串
+func parseIANAWhoisServer(data string) string {
+	for _, matches := range whoisServerPattern.FindAllStringSubmatch(data, -1) {
+		if matches[1] != "" {
+			return strings.ToLower(matches[1])
 		}
-		resultChan <- result{data: data, err: err}
-	}()
-
-	// 等待结果或超时
-	select {
-	case res := <-resultChan:
-		if res.err != nil {
-			return nil, fmt.Errorf("whois查询失败: %v", res.err)
+	}
+	return ""
+}
+
+// whoisQuery 向指定WHOIS服务器执行查询（超时和取消由ctx控制）
+func whoisQuery(ctx context.Context, query, server string) (string, error) {
+	// whois客户端会按timeout设置连接读写截止时间，这里与ctx截止时间保持一致
+	timeout := 30 * time.Second
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	client := whois.NewClient().
+		SetDialer(&contextDialer{ctx: ctx}).
+		SetTimeout(timeout)
+
+	slog.DebugContext(ctx, "执行WHOIS查询", "query", query, "server", server, "timeout", timeout)
+	data, err := client.Whois(query, server)
+	if err != nil {
+		if ctx.Err() != nil {
+			slog.DebugContext(ctx, "WHOIS查询超时或已取消", "query", query, "server", server)
+			// 超时或取消时以ctx的错误为准
+			return "", fmt.Errorf("whois查询失败: %w", wrapNetworkError(ctx.Err()))
 		}
-		return parseDomainInfo(domain, res.data)
-	case <-ctx.Done():
-		slog.Debug("WHOIS查询超时", "domain", domain, "timeout", timeout)
-		return nil, fmt.Errorf("whois查询超时: %v", ctx.Err())
+		slog.DebugContext(ctx, "WHOIS查询失败", "query", query, "error", err)
+		return "", fmt.Errorf("whois查询失败: %w", wrapNetworkError(err))
 	}
+
+	slog.DebugContext(ctx, "WHOIS查询成功", "query", query, "data_length", len(data))
+	return data, nil
+}
+
+// contextDialer 绑定ctx的拨号器：拨号受ctx控制，ctx取消时关闭连接以中断阻塞中的读写
+type contextDialer struct {
+	ctx context.Context
+}
+
+// Dial 实现proxy.Dialer接口
+func (d *contextDialer) Dial(network, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(d.ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := d.ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	stop := context.AfterFunc(d.ctx, func() {
+		conn.Close()
+	})
+	return &contextConn{Conn: conn, stop: stop}, nil
+}
+
+// contextConn 关闭时同时解除ctx回调的连接
+type contextConn struct {
+	net.Conn
+	stop func() bool
+}
+
+// Close 关闭连接
+func (c *contextConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
 }
 
 // parseDomainInfo 解析域名信息
-func parseDomainInfo(domain, whoisData string) (*DomainInfo, error) {
-	slog.Debug("开始解析WHOIS数据", "domain", domain, "data_length", len(whoisData))
+func parseDomainInfo(ctx context.Context, domain, whoisData string) (*DomainInfo, error) {
+	slog.DebugContext(ctx, "开始解析WHOIS数据", "domain", domain, "data_length", len(whoisData))
 	
 	// 打印WHOIS原始数据的前500字符用于调试
 	if len(whoisData) > 0 {
@@ -72,33 +200,39 @@ func parseDomainInfo(domain, whoisData string) (*DomainInfo, error) {
 		if len(preview) > 500 {
 			preview = preview[:500] + "..."
 		}
-		slog.Debug("WHOIS原始数据预览", "domain", domain, "data", preview)
+		slog.DebugContext(ctx, "WHOIS原始数据预览", "domain", domain, "data", preview)
 	}
 
 	// 解析whois信息
 	parsed, err := whoisparser.Parse(whoisData)
 	if err != nil {
-		slog.Error("WHOIS解析失败", "domain", domain, "error", err, "raw_data_length", len(whoisData))
-		return nil, fmt.Errorf("whois解析失败: %v", err)
+		slog.ErrorContext(ctx, "WHOIS解析失败", "domain", domain, "error", err, "raw_data_length", len(whoisData))
+		return nil, fmt.Errorf("whois解析失败: %w", wrapWhoisParserError(err))
 	}
 	
-	slog.Debug("WHOIS解析成功", "domain", domain, 
-		"registrar", parsed.Registrar.Name,
+	// 部分WHOIS响应没有注册商信息
+	var registrar whoisparser.Contact
+	if parsed.Registrar != nil {
+		registrar = *parsed.Registrar
+	}
+	
+	slog.DebugContext(ctx, "WHOIS解析成功", "domain", domain, 
+		"registrar", registrar.Name,
 		"expiration_date", parsed.Domain.ExpirationDate,
 		"status_count", len(parsed.Domain.Status))
 
 	// 检查解析结果
 	if parsed.Domain.ExpirationDate == "" {
-		slog.Error("WHOIS解析结果中没有过期时间", "domain", domain, 
-			"registrar", parsed.Registrar.Name,
+		slog.ErrorContext(ctx, "WHOIS解析结果中没有过期时间", "domain", domain, 
+			"registrar", registrar.Name,
 			"domain_name", parsed.Domain.Name)
 		
 		// 尝试从原始数据中手动提取过期时间
-		return parseExpirationFromRawData(domain, whoisData)
+		return parseExpirationFromRawData(ctx, domain, whoisData)
 	}
 
 	// 解析过期时间
-	slog.Debug("尝试解析过期时间", "domain", domain, "expiration_date", parsed.Domain.ExpirationDate)
+	slog.DebugContext(ctx, "尝试解析过期时间", "domain", domain, "expiration_date", parsed.Domain.ExpirationDate)
 	
 	expiryDate, err := time.Parse("2006-01-02T15:04:05Z", parsed.Domain.ExpirationDate)
 	if err != nil {
@@ -115,15 +249,15 @@ func parseDomainInfo(domain, whoisData string) (*DomainInfo, error) {
 		
 		for _, format := range formats {
 			if expiryDate, err = time.Parse(format, parsed.Domain.ExpirationDate); err == nil {
-				slog.Debug("成功解析过期时间", "domain", domain, "format", format, "date", expiryDate)
+				slog.DebugContext(ctx, "成功解析过期时间", "domain", domain, "format", format, "date", expiryDate)
 				break
 			}
 		}
 		
 		if err != nil {
-			slog.Error("无法解析过期时间", "domain", domain, "expiration_date", parsed.Domain.ExpirationDate, "error", err)
+			slog.ErrorContext(ctx, "无法解析过期时间", "domain", domain, "expiration_date", parsed.Domain.ExpirationDate, "error", err)
 			// 尝试从原始数据中手动提取
-			return parseExpirationFromRawData(domain, whoisData)
+			return parseExpirationFromRawData(ctx, domain, whoisData)
 		}
 	}
 
@@ -136,48 +270,87 @@ func parseDomainInfo(domain, whoisData string) (*DomainInfo, error) {
 	}
 
 	return &DomainInfo{
-		Domain:     domain,
-		ExpiryDate: expiryDate,
-		Registrar:  parsed.Registrar.Name,
-		Status:     status,
-		Method:     "whois",
+		Domain:          domain,
+		ExpiryDate:      expiryDate,
+		CreatedDate:     parsedWhoisDate(parsed.Domain.CreatedDateInTime, parsed.Domain.CreatedDate),
+		UpdatedDate:     parsedWhoisDate(parsed.Domain.UpdatedDateInTime, parsed.Domain.UpdatedDate),
+		Registrar:       registrar.Name,
+		RegistrarIANAID: registrar.ID,
+		WhoisServer:     parsed.Domain.WhoisServer,
+		Status:          status,
+		Statuses:        parsed.Domain.Status,
+		Method:          "whois",
 	}, nil
 }
 
-// GetDomainInfoWithFallback 使用WHOIS获取域名信息（带重试）
-func GetDomainInfoWithFallback(domain string, timeout time.Duration, config *Config) (*DomainInfo, error) {
+// parsedWhoisDate 获取whois-parser解析的日期，未能解析时尝试更多格式，仍失败时返回零值
+func parsedWhoisDate(parsed *time.Time, raw string) time.Time {
+	if parsed != nil {
+		return *parsed
+	}
+	if raw == "" {
+		return time.Time{}
+	}
+	date, err := parseFlexibleDate(raw)
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// GetDomainInfoWithFallback 按配置的提供者链获取域名信息，前一个提供者失败时回退到下一个；
+// 有提供者明确返回域名不存在且没有提供者成功时返回ErrDomainNotFound，不被后续提供者的临时错误覆盖
+func GetDomainInfoWithFallback(ctx context.Context, domain string, config *Config) (*DomainInfo, error) {
 	maxRetries := 2
-	var lastErr error
-	
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		slog.Debug("WHOIS查询尝试", "domain", domain, "attempt", attempt, "max_retries", maxRetries)
-		
-		info, err := GetDomainInfo(domain, timeout)
+	chain := config.ProviderChain(domain)
+	var lastErr, notFoundErr error
+
+	for _, name := range chain {
+		provider, err := newProvider(name, config)
+		if err != nil {
+			slog.WarnContext(ctx, "跳过无效的提供者", "domain", domain, "provider", name, "error", err)
+			continue
+		}
+
+		info, err := lookupWithRetry(ctx, provider, domain, maxRetries)
 		if err == nil {
-			if attempt > 1 {
-				slog.Info("WHOIS查询重试成功", "domain", domain, "attempt", attempt)
-			}
+			info.State = lifecycleState(info, time.Now())
 			return info, nil
 		}
-		
+
+		if errors.Is(err, errProviderNotApplicable) {
+			slog.DebugContext(ctx, "提供者不适用，尝试下一个", "domain", domain, "provider", name)
+			continue
+		}
+
+		// 监控已停止，不再尝试后续提供者
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("域名查询已取消: %w", ctx.Err())
+		}
+
 		lastErr = err
-		slog.Debug("WHOIS查询失败", "domain", domain, "attempt", attempt, "error", err)
-		
-		// 如果不是最后一次尝试，等待一下再重试
-		if attempt < maxRetries {
-			waitTime := time.Duration(attempt) * time.Second
-			slog.Debug("等待重试", "domain", domain, "wait_seconds", waitTime.Seconds())
-			time.Sleep(waitTime)
+		// 保留明确的域名不存在结果，后续提供者的超时、限速等临时错误不能覆盖它
+		if notFoundErr == nil && errors.Is(err, ErrDomainNotFound) {
+			notFoundErr = err
 		}
+		slog.DebugContext(ctx, "提供者查询失败，尝试下一个", "domain", domain, "provider", name, "error", err)
 	}
-	
-	slog.Error("所有WHOIS查询尝试都失败了", "domain", domain, "attempts", maxRetries, "last_error", lastErr)
-	return nil, fmt.Errorf("WHOIS查询失败: %v", lastErr)
+
+	if notFoundErr != nil {
+		slog.InfoContext(ctx, "提供者返回域名不存在", "domain", domain, "providers", chain, "error", notFoundErr)
+		return nil, fmt.Errorf("域名查询失败: %w", notFoundErr)
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("没有可用的提供者: %v", chain)
+	}
+
+	slog.ErrorContext(ctx, "所有提供者查询都失败了", "domain", domain, "providers", chain, "last_error", lastErr)
+	return nil, fmt.Errorf("域名查询失败: %w", lastErr)
 }
 
 // parseExpirationFromRawData 从原始WHOIS数据中手动提取过期时间
-func parseExpirationFromRawData(domain, whoisData string) (*DomainInfo, error) {
-	slog.Debug("尝试从原始数据手动解析过期时间", "domain", domain)
+func parseExpirationFromRawData(ctx context.Context, domain, whoisData string) (*DomainInfo, error) {
+	slog.DebugContext(ctx, "尝试从原始数据手动解析过期时间", "domain", domain)
 	
 	// 常见的过期时间字段名
 	expirationPatterns := []string{
@@ -203,6 +376,7 @@ func parseExpirationFromRawData(domain, whoisData string) (*DomainInfo, error) {
 	var expiryDate time.Time
 	var registrar string
 	var found bool
+	var dateErr error // 找到了过期时间字段但无法解析时的错误
 	
 	// 尝试提取过期时间
 	for _, pattern := range expirationPatterns {
@@ -210,22 +384,26 @@ func parseExpirationFromRawData(domain, whoisData string) (*DomainInfo, error) {
 		matches := re.FindStringSubmatch(whoisData)
 		if len(matches) > 1 {
 			dateStr := strings.TrimSpace(matches[1])
-			slog.Debug("找到过期时间字段", "domain", domain, "pattern", pattern, "date_str", dateStr)
+			slog.DebugContext(ctx, "找到过期时间字段", "domain", domain, "pattern", pattern, "date_str", dateStr)
 			
 			// 尝试解析日期
 			if parsedDate, err := parseFlexibleDate(dateStr); err == nil {
 				expiryDate = parsedDate
 				found = true
-				slog.Debug("成功解析过期时间", "domain", domain, "date", expiryDate)
+				slog.DebugContext(ctx, "成功解析过期时间", "domain", domain, "date", expiryDate)
 				break
 			} else {
-				slog.Debug("解析日期失败", "domain", domain, "date_str", dateStr, "error", err)
+				slog.DebugContext(ctx, "解析日期失败", "domain", domain, "date_str", dateStr, "error", err)
+				dateErr = err
 			}
 		}
 	}
 	
 	if !found {
-		return nil, fmt.Errorf("无法从原始数据中提取过期时间")
+		if dateErr != nil {
+			return nil, fmt.Errorf("无法从原始数据中提取过期时间: %w", dateErr)
+		}
+		return nil, fmt.Errorf("无法从原始数据中提取过期时间: %w", ErrMissingExpiry)
 	}
 	
 	// 尝试提取注册商
@@ -242,15 +420,69 @@ func parseExpirationFromRawData(domain, whoisData string) (*DomainInfo, error) {
 		registrar = "Unknown"
 	}
 	
+	// 提取EPP状态码，用于输出状态码指标和判断生命周期状态
+	var statuses []string
+	for _, matches := range rawStatusPattern.FindAllStringSubmatch(whoisData, -1) {
+		statuses = append(statuses, strings.TrimSpace(matches[1]))
+	}
+	status := "unknown"
+	if len(statuses) > 0 {
+		status = statuses[0]
+	}
+	
 	return &DomainInfo{
-		Domain:     domain,
-		ExpiryDate: expiryDate,
-		Registrar:  registrar,
-		Status:     "active",
-		Method:     "whois(manual_parse)",
+		Domain:          domain,
+		ExpiryDate:      expiryDate,
+		CreatedDate:     findRawDate(whoisData, rawCreatedPatterns),
+		UpdatedDate:     findRawDate(whoisData, rawUpdatedPatterns),
+		Registrar:       registrar,
+		RegistrarIANAID: findRawField(whoisData, rawRegistrarIANAIDPattern),
+		WhoisServer:     findRawField(whoisData, rawWhoisServerPattern),
+		Status:          status,
+		Statuses:        statuses,
+		Method:          "whois(manual_parse)",
 	}, nil
 }
 
+// 原始WHOIS数据中的状态、注册商和日期字段
+var (
+	rawStatusPattern          = regexp.MustCompile(`(?im)^\s*(?:Domain )?Status:\s*(.+)$`)
+	rawRegistrarIANAIDPattern = regexp.MustCompile(`(?i)Registrar IANA ID:\s*(\d+)`)
+	rawWhoisServerPattern     = regexp.MustCompile(`(?i)Registrar WHOIS Server:\s*(\S+)`)
+	rawCreatedPatterns        = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)Creation Date:\s*(.+)`),
+		regexp.MustCompile(`(?i)Registration Time:\s*(.+)`),
+		regexp.MustCompile(`(?i)Created(?: On| Date)?:\s*(.+)`),
+	}
+	rawUpdatedPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)Updated Date:\s*(.+)`),
+		regexp.MustCompile(`(?i)Last Modified:\s*(.+)`),
+		regexp.MustCompile(`(?i)Last Updated(?: On)?:\s*(.+)`),
+	}
+)
+
+// findRawField 从原始WHOIS数据中提取字段值，未找到时返回空字符串
+func findRawField(whoisData string, pattern *regexp.Regexp) string {
+	if matches := pattern.FindStringSubmatch(whoisData); len(matches) > 1 {
+		return strings.TrimSpace(matches[1])
+	}
+	return ""
+}
+
+// findRawDate 从原始WHOIS数据中提取日期，依次尝试各字段名，均未找到或无法解析时返回零值
+func findRawDate(whoisData string, patterns []*regexp.Regexp) time.Time {
+	for _, pattern := range patterns {
+		value := findRawField(whoisData, pattern)
+		if value == "" {
+			continue
+		}
+		if date, err := parseFlexibleDate(value); err == nil {
+			return date
+		}
+	}
+	return time.Time{}
+}
+
 // parseFlexibleDate 灵活解析各种日期格式
 func parseFlexibleDate(dateStr string) (time.Time, error) {
 	// 清理日期字符串
@@ -287,5 +519,5 @@ func parseFlexibleDate(dateStr string) (time.Time, error) {
 		}
 	}
 	
-	return time.Time{}, fmt.Errorf("无法解析日期格式: %s", dateStr)
+	return time.Time{}, fmt.Errorf("%w: %s", ErrUnparseableDate, dateStr)
 }
\ No newline at end of file
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseIANAWhoisServer(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "refer和whois字段",
+			data: "% IANA WHOIS server\n\nrefer:        whois.verisign-grs.com\n\ndomain:       COM\n\nwhois:        whois.verisign-grs.com\nstatus:       ACTIVE\n",
+			want: "whois.verisign-grs.com",
+		},
+		{
+			name: "只有whois字段",
+			data: "domain:       IO\n\nwhois:        WHOIS.NIC.IO\n\nstatus:       ACTIVE\n",
+			want: "whois.nic.io",
+		},
+		{
+			name: "whois字段为空时不匹配下一行",
+			data: "domain:       AE\n\nwhois:\nstatus:       ACTIVE\nremarks:      Registration information: http://www.nic.ae\n",
+			want: "",
+		},
+		{
+			name: "whois字段为空但有refer字段",
+			data: "refer:        whois.nic.example\n\ndomain:       EXAMPLE\nwhois:  \t\nstatus:       ACTIVE\n",
+			want: "whois.nic.example",
+		},
+		{
+			name: "CRLF换行",
+			data: "domain:       AE\r\nwhois:\r\nstatus:       ACTIVE\r\n",
+			want: "",
+		},
+		{
+			name: "没有WHOIS服务器字段",
+			data: "domain:       EXAMPLE\nstatus:       ACTIVE\n",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseIANAWhoisServer(tt.data); got != tt.want {
+				t.Errorf("parseIANAWhoisServer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// stubProvider 返回固定结果的提供者
+type stubProvider struct {
+	name  string
+	info  *DomainInfo
+	err   error
+	calls int
+}
+
+func (p *stubProvider) Name() string { return p.name }
+
+func (p *stubProvider) Lookup(ctx context.Context, domain string) (*DomainInfo, error) {
+	p.calls++
+	return p.info, p.err
+}
+
+func TestGetDomainInfoWithFallbackKeepsNotFound(t *testing.T) {
+	expiry := time.Now().AddDate(1, 0, 0)
+	rdap := &stubProvider{name: "test-rdap", err: fmt.Errorf("rdap查询失败: %w", ErrDomainNotFound)}
+	whoisTimeout := &stubProvider{name: "test-whois-timeout", err: fmt.Errorf("whois查询失败: %w", wrapNetworkError(context.DeadlineExceeded))}
+	whoisOK := &stubProvider{name: "test-whois-ok", info: &DomainInfo{Domain: "example.com", ExpiryDate: expiry, Method: "whois"}}
+	for _, provider := range []*stubProvider{rdap, whoisTimeout, whoisOK} {
+		RegisterProvider(provider.name, func(*Config) DomainInfoProvider { return provider })
+	}
+
+	t.Run("rdap返回404且whois超时", func(t *testing.T) {
+		config := &Config{Providers: ProviderConfig{Default: []string{"test-rdap", "test-whois-timeout"}}}
+		_, err := GetDomainInfoWithFallback(context.Background(), "example.com", config)
+		if !errors.Is(err, ErrDomainNotFound) {
+			t.Fatalf("error = %v, want ErrDomainNotFound", err)
+		}
+		if errors.Is(err, ErrLookupTimeout) {
+			t.Errorf("error = %v, 不应返回后续提供者的超时错误", err)
+		}
+		if whoisTimeout.calls == 0 {
+			t.Error("rdap返回404后仍应尝试whois")
+		}
+		if reason := classifyCheckError(err); reason != checkErrorNotFound {
+			t.Errorf("classifyCheckError() = %q, want %q", reason, checkErrorNotFound)
+		}
+	})
+
+	t.Run("rdap返回404但whois成功", func(t *testing.T) {
+		config := &Config{Providers: ProviderConfig{Default: []string{"test-rdap", "test-whois-ok"}}}
+		info, err := GetDomainInfoWithFallback(context.Background(), "example.com", config)
+		if err != nil {
+			t.Fatalf("error = %v", err)
+		}
+		if !info.ExpiryDate.Equal(expiry) || info.State != lifecycleRegistered {
+			t.Errorf("info = %+v", info)
+		}
+	})
+}